@@ -0,0 +1,297 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+// Package aegis reads and writes the JSON vault format used by the Aegis
+// Authenticator app for Android to export and back up its entries.
+//
+// A vault is either plain, or encrypted with AES-256-GCM under a random
+// master key. The master key is stored in one or more "slots", each of which
+// encrypts it with another key. This package supports password slots, whose
+// key is derived from a password with scrypt; other slot types, such as the
+// biometric slots used on the device, are ignored.
+//
+// The settings of each entry are represented as an otpauth URL. Steam entries
+// use the "steam" encoder, Yandex entries use the yaotp type, and metadata
+// that has no otpauth equivalent is kept in URL parameters (see GroupParam and
+// NoteParam). The PIN of a Yandex entry is a secret, and is kept apart from
+// the URL in the PIN field of an Entry.
+//
+// See https://github.com/beemdevelopment/Aegis/blob/master/docs/vault.md
+package aegis
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+
+	"github.com/creachadair/otp"
+	"github.com/creachadair/otp/otpauth"
+)
+
+// Parameter keys used to store Aegis metadata in the Extra field of a URL.
+const (
+	// GroupParam is the name of a group the entry belongs to. An entry in
+	// several groups has one parameter for each group, in order.
+	GroupParam = "group"
+
+	// NoteParam is the free-form note attached to the entry.
+	NoteParam = "note"
+)
+
+// An Entry is an entry of an Aegis vault.
+type Entry struct {
+	URL *otpauth.URL // the settings and metadata of the entry
+
+	// PIN is the PIN stored with a Yandex entry, or "" if none. It is not
+	// kept in the URL, since the string form of a URL includes all its
+	// parameters.
+	PIN string
+}
+
+// Options carry the settings used to decrypt or encrypt a vault.
+// A nil *Options is ready for use, and supports only plain vaults.
+type Options struct {
+	// Password is used to unlock an encrypted vault. When writing, if set,
+	// the vault is encrypted with a single password slot.
+	Password string
+
+	// Cost is the scrypt CPU/memory cost parameter (N) used for the
+	// password slot when writing. It must be a power of 2 no greater than
+	// 262144, the largest cost Parse accepts for the slot. If zero, the
+	// default is 32768, the value used by Aegis.
+	Cost int
+}
+
+func (o *Options) password() string {
+	if o == nil {
+		return ""
+	}
+	return o.Password
+}
+
+func (o *Options) cost() int {
+	if o == nil || o.Cost == 0 {
+		return 1 << 15
+	}
+	return o.Cost
+}
+
+// Parse parses data as an Aegis vault and returns its entries, in the order
+// they appear in the vault. If the vault is encrypted, it is
+// unlocked with the password in opts. If the password does not unlock the
+// vault, the error reported satisfies errors.Is(err, ErrPassword).
+//
+// Parse reports an error if the vault contains an entry whose type cannot be
+// represented as an otpauth URL, such as Mobile-OTP.
+func Parse(data []byte, opts *Options) ([]*Entry, error) {
+	var v vaultFile
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("invalid vault: %w", err)
+	}
+	if v.Version != vaultVersion {
+		return nil, fmt.Errorf("unsupported vault version %d", v.Version)
+	}
+
+	dbData := []byte(v.DB)
+	if v.Header.Params != nil {
+		var err error
+		dbData, err = decryptVault(&v, opts.password())
+		if err != nil {
+			return nil, err
+		}
+	}
+	var db database
+	if err := json.Unmarshal(dbData, &db); err != nil {
+		return nil, fmt.Errorf("invalid database: %w", err)
+	}
+	if db.Version < 1 || db.Version > dbVersion {
+		return nil, fmt.Errorf("unsupported database version %d", db.Version)
+	}
+
+	groups := make(map[string]string) // UUID → name
+	for _, g := range db.Groups {
+		groups[g.UUID] = g.Name
+	}
+	out := make([]*Entry, len(db.Entries))
+	for i, e := range db.Entries {
+		u, err := e.url(groups)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d (%q): %w", i+1, e.Name, err)
+		}
+		out[i] = &Entry{URL: u}
+		if e.Type == "yandex" {
+			out[i].PIN = e.Info.PIN
+		}
+	}
+	return out, nil
+}
+
+// url converts e to an otpauth URL, resolving group UUIDs with groups.
+func (e *entry) url(groups map[string]string) (*otpauth.URL, error) {
+	u := &otpauth.URL{
+		Issuer:  e.Issuer,
+		Account: e.Name,
+		Digits:  e.Info.Digits,
+		Period:  e.Info.Period,
+	}
+	switch e.Type {
+	case "totp":
+		u.Type = "totp"
+	case "hotp":
+		u.Type = "hotp"
+		if e.Info.Counter != nil {
+			u.Counter = *e.Info.Counter
+		}
+		u.Period = 0
+	case "steam":
+		u.Type = "totp"
+		u.Encoder = "steam"
+	case "yandex":
+		u.Type = "yaotp"
+		u.PINLength = len(e.Info.PIN)
+	default:
+		return nil, fmt.Errorf("unsupported entry type %q", e.Type)
+	}
+
+	alg, err := otp.ParseAlgorithm(e.Info.Algo)
+	if err != nil {
+		return nil, err
+	}
+	u.Algorithm = alg.String()
+	key, err := otp.ParseKey(e.Info.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret: %w", err)
+	}
+	u.SetSecret(key)
+
+	// Database version 2 and earlier store a single group name on the entry;
+	// later versions refer to the groups listed in the database.
+	if e.Group != "" {
+		u.Extra = append(u.Extra, otpauth.Param{Key: GroupParam, Value: e.Group})
+	}
+	for _, id := range e.Groups {
+		name, ok := groups[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown group %q", id)
+		}
+		u.Extra = append(u.Extra, otpauth.Param{Key: GroupParam, Value: name})
+	}
+	if e.Note != "" {
+		u.SetParam(NoteParam, e.Note)
+	}
+	return u, nil
+}
+
+// Marshal encodes entries as an Aegis vault. If opts specifies a password,
+// the vault is encrypted with a single password slot; otherwise it is plain.
+//
+// Marshal reports an error if an entry cannot be represented in the vault.
+// The Preset field and any Extra parameters of a URL not described by this
+// package are not recorded.
+func Marshal(entries []*Entry, opts *Options) ([]byte, error) {
+	db := &database{Version: dbVersion, Entries: make([]*entry, len(entries))}
+	groups := make(map[string]string) // name → UUID
+	for i, ent := range entries {
+		u := ent.URL
+		e, err := newEntry(u)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d (%q): %w", i+1, u.Account, err)
+		}
+		if e.Type == "yandex" {
+			e.Info.PIN = ent.PIN
+		}
+		for _, p := range u.Extra {
+			if p.Key != GroupParam {
+				continue
+			}
+			id, ok := groups[p.Value]
+			if !ok {
+				id = newUUID()
+				groups[p.Value] = id
+				db.Groups = append(db.Groups, &group{UUID: id, Name: p.Value})
+			}
+			e.Groups = append(e.Groups, id)
+		}
+		db.Entries[i] = e
+	}
+	dbData, err := json.Marshal(db)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &vaultFile{Version: vaultVersion, DB: dbData}
+	if pw := opts.password(); pw != "" {
+		if err := encryptVault(v, pw, opts.cost()); err != nil {
+			return nil, err
+		}
+	}
+	return json.MarshalIndent(v, "", "    ")
+}
+
+// newEntry converts u to an Aegis entry, without its groups or PIN.
+func newEntry(u *otpauth.URL) (*entry, error) {
+	e := &entry{
+		UUID:   newUUID(),
+		Name:   u.Account,
+		Issuer: u.Issuer,
+		Info: info{
+			Algo:   "SHA1",
+			Digits: u.Digits,
+			Period: u.Period,
+		},
+	}
+	switch {
+	case u.Type == "totp" && u.Encoder == "":
+		e.Type = "totp"
+	case u.Type == "totp" && u.Encoder == "steam":
+		e.Type = "steam"
+	case u.Type == "hotp" && u.Encoder == "":
+		e.Type = "hotp"
+		counter := u.Counter
+		e.Info.Counter = &counter
+		e.Info.Period = 0
+	case u.Type == "yaotp" && u.Encoder == "":
+		e.Type = "yandex"
+	case u.Encoder != "":
+		return nil, fmt.Errorf("unsupported encoder %q for type %q", u.Encoder, u.Type)
+	default:
+		return nil, fmt.Errorf("unsupported type %q", u.Type)
+	}
+
+	if u.Algorithm != "" {
+		alg, err := otp.ParseAlgorithm(u.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		e.Info.Algo = alg.String()
+	}
+	if e.Info.Digits <= 0 {
+		e.Info.Digits = defaultDigits[e.Type]
+	}
+	if e.Info.Period <= 0 && e.Type != "hotp" {
+		e.Info.Period = 30
+	}
+	key, err := u.Secret()
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret: %w", err)
+	}
+	e.Info.Secret = sec32.EncodeToString(key)
+	e.Note, _ = u.Param(NoteParam)
+	return e, nil
+}
+
+// defaultDigits gives the default code length for each entry type.
+var defaultDigits = map[string]int{
+	"totp": 6, "hotp": 6, "steam": otp.SteamDigits, "yandex": otp.YandexDigits,
+}
+
+var sec32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newUUID returns a random (version 4) UUID in the standard string format.
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
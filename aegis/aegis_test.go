@@ -0,0 +1,364 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package aegis_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creachadair/otp/aegis"
+	"github.com/creachadair/otp/otpauth"
+	"github.com/google/go-cmp/cmp"
+)
+
+// A plain vault in the format exported by Aegis.
+const plainVault = `{
+    "version": 1,
+    "header": {
+        "slots": null,
+        "params": null
+    },
+    "db": {
+        "version": 3,
+        "entries": [
+            {
+                "type": "totp",
+                "uuid": "3ae6f1ad-2e65-4ed2-a953-1ec0dff2386d",
+                "name": "alice@example.com",
+                "issuer": "Example",
+                "note": "primary account",
+                "favorite": true,
+                "icon": null,
+                "info": {
+                    "secret": "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+                    "algo": "SHA256",
+                    "digits": 8,
+                    "period": 60
+                },
+                "groups": [
+                    "a6a2b0a4-4f34-4e9e-8c2e-2f4f7a0a1d11",
+                    "c1f0e0b2-6d7b-4c44-9e2e-0b9e6c2d8f22"
+                ]
+            },
+            {
+                "type": "hotp",
+                "uuid": "7f0d1b8a-92b0-4c4c-8d45-2a6b0f1c3e44",
+                "name": "bob",
+                "issuer": "",
+                "note": "",
+                "favorite": false,
+                "icon": null,
+                "info": {
+                    "secret": "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+                    "algo": "SHA1",
+                    "digits": 6,
+                    "counter": 5
+                }
+            },
+            {
+                "type": "steam",
+                "uuid": "e8d8c2f4-1b7a-4f36-8c1e-5d2e9b7a6c55",
+                "name": "gamer",
+                "issuer": "Steam",
+                "note": "",
+                "favorite": false,
+                "icon": null,
+                "info": {
+                    "secret": "AEBAGBAFAYDQQCIKBMGA2DQPCA",
+                    "algo": "SHA1",
+                    "digits": 5,
+                    "period": 30
+                },
+                "groups": ["c1f0e0b2-6d7b-4c44-9e2e-0b9e6c2d8f22"]
+            },
+            {
+                "type": "yandex",
+                "uuid": "0c9b6e2a-3d4f-4a8b-b1c2-7e6f5d4c3b66",
+                "name": "user",
+                "issuer": "Yandex",
+                "note": "",
+                "favorite": false,
+                "icon": null,
+                "info": {
+                    "secret": "6SB2IKNM6OBZPAVBVTOHDKS4FA",
+                    "algo": "SHA256",
+                    "digits": 8,
+                    "period": 30,
+                    "pin": "5239"
+                }
+            }
+        ],
+        "groups": [
+            {"uuid": "a6a2b0a4-4f34-4e9e-8c2e-2f4f7a0a1d11", "name": "Work"},
+            {"uuid": "c1f0e0b2-6d7b-4c44-9e2e-0b9e6c2d8f22", "name": "Personal"}
+        ]
+    }
+}`
+
+var plainEntries = []*aegis.Entry{{URL: &otpauth.URL{
+	Type: "totp", Issuer: "Example", Account: "alice@example.com",
+	RawSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+	Algorithm: "SHA256", Digits: 8, Period: 60,
+	Extra: []otpauth.Param{
+		{Key: aegis.GroupParam, Value: "Work"},
+		{Key: aegis.GroupParam, Value: "Personal"},
+		{Key: aegis.NoteParam, Value: "primary account"},
+	},
+}}, {URL: &otpauth.URL{
+	Type: "hotp", Account: "bob",
+	RawSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+	Algorithm: "SHA1", Digits: 6, Counter: 5,
+}}, {URL: &otpauth.URL{
+	Type: "totp", Issuer: "Steam", Account: "gamer",
+	RawSecret: "AEBAGBAFAYDQQCIKBMGA2DQPCA",
+	Algorithm: "SHA1", Digits: 5, Period: 30, Encoder: "steam",
+	Extra: []otpauth.Param{{Key: aegis.GroupParam, Value: "Personal"}},
+}}, {URL: &otpauth.URL{
+	Type: "yaotp", Issuer: "Yandex", Account: "user",
+	RawSecret: "6SB2IKNM6OBZPAVBVTOHDKS4FA",
+	Algorithm: "SHA256", Digits: 8, Period: 30, PINLength: 4,
+}, PIN: "5239"}}
+
+func TestParse(t *testing.T) {
+	entries, err := aegis.Parse([]byte(plainVault), nil)
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(entries, plainEntries); diff != "" {
+		t.Errorf("Parse (-got, +want):\n%s", diff)
+	}
+
+	// The Yandex entry should generate the reference code for its PIN.
+	// The PIN is not exposed in the URL string.
+	yandex := entries[3]
+	if got := yandex.URL.String(); strings.Contains(got, yandex.PIN) {
+		t.Errorf("URL %q contains the PIN", got)
+	}
+	cfg, err := yandex.URL.YandexConfig(yandex.PIN)
+	if err != nil {
+		t.Fatalf("YandexConfig: unexpected error: %v", err)
+	}
+	if got, want := cfg.TOTPAt(time.Unix(1641559648, 0)), "umozdicq"; got != want {
+		t.Errorf("Yandex code: got %q, want %q", got, want)
+	}
+}
+
+func TestParseEncrypted(t *testing.T) {
+	// The vaults in testdata were generated by testdata/mkvault.js, which uses
+	// an independent implementation of scrypt and AES-GCM.
+	alice := &aegis.Entry{URL: &otpauth.URL{
+		Type: "totp", Issuer: "Example", Account: "alice@example.com",
+		RawSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		Algorithm: "SHA1", Digits: 6, Period: 30,
+	}}
+	tests := []struct {
+		file, password string
+		want           []*aegis.Entry
+	}{
+		{"encrypted.json", "test", []*aegis.Entry{{URL: &otpauth.URL{
+			Type: "totp", Issuer: "Steam", Account: "gamer",
+			RawSecret: "ON2XAZLSMR2XAZLSONSWG4TFOQ",
+			Algorithm: "SHA1", Digits: 5, Period: 30, Encoder: "steam",
+			Extra: []otpauth.Param{{Key: aegis.GroupParam, Value: "Games"}},
+		}}, alice}},
+
+		// A slot not marked as repaired uses the legacy password encoding.
+		{"legacy.json", "0123456789", []*aegis.Entry{alice}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tc.file))
+			if err != nil {
+				t.Fatalf("Reading vault: %v", err)
+			}
+			entries, err := aegis.Parse(data, &aegis.Options{Password: tc.password})
+			if err != nil {
+				t.Fatalf("Parse: unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(entries, tc.want); diff != "" {
+				t.Errorf("Parse (-got, +want):\n%s", diff)
+			}
+
+			if _, err := aegis.Parse(data, &aegis.Options{Password: "wrong"}); !errors.Is(err, aegis.ErrPassword) {
+				t.Errorf("Parse with wrong password: got %v, want %v", err, aegis.ErrPassword)
+			}
+		})
+	}
+
+	// The secret of the Steam entry is "superdupersecret", for which the
+	// ValvePython/steam library publishes reference codes.
+	data, err := os.ReadFile(filepath.Join("testdata", "encrypted.json"))
+	if err != nil {
+		t.Fatalf("Reading vault: %v", err)
+	}
+	entries, err := aegis.Parse(data, &aegis.Options{Password: "test"})
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	cfg, err := entries[0].URL.Config()
+	if err != nil {
+		t.Fatalf("Config: unexpected error: %v", err)
+	}
+	for _, tc := range []struct {
+		unix int64
+		want string
+	}{
+		{3000030, "YRGQJ"},
+		{3000029, "94R9D"},
+	} {
+		if got := cfg.TOTPAt(time.Unix(tc.unix, 0)); got != tc.want {
+			t.Errorf("TOTPAt(%d): got %q, want %q", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	// A slot asking for excessive scrypt parameters should be rejected without
+	// attempting to derive the key.
+	data, err := os.ReadFile(filepath.Join("testdata", "encrypted.json"))
+	if err != nil {
+		t.Fatalf("Reading vault: %v", err)
+	}
+	for _, tc := range []struct {
+		old, new string
+	}{
+		{`"n": 32768`, `"n": 1073741824`},
+		{`"n": 32768`, `"n": 1048576`}, // with r=8, needs 1 GiB
+		{`"r": 8`, `"r": 1048576`},
+		{`"p": 1`, `"p": 1048576`},
+	} {
+		t.Run(tc.new, func(t *testing.T) {
+			input := strings.Replace(string(data), tc.old, tc.new, 1)
+			entries, err := aegis.Parse([]byte(input), &aegis.Options{Password: "test"})
+			if err == nil {
+				t.Fatalf("Parse: got %v, want error", entries)
+			} else if !strings.Contains(err.Error(), "exceed limits") {
+				t.Errorf("Parse: got error %v, want limit error", err)
+			}
+		})
+	}
+}
+
+func TestParseVersion2(t *testing.T) {
+	// Database version 2 records a group name directly on the entry.
+	const input = `{"version": 1, "header": {"slots": null, "params": null}, "db": {
+  "version": 2,
+  "entries": [{
+    "type": "totp", "uuid": "3ae6f1ad-2e65-4ed2-a953-1ec0dff2386d",
+    "name": "alice", "issuer": "Example", "group": "Work", "icon": null,
+    "info": {"secret": "GEZDGNBVGY3TQOJQ", "algo": "SHA1", "digits": 6, "period": 30}
+  }]
+}}`
+	entries, err := aegis.Parse([]byte(input), nil)
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(entries, []*aegis.Entry{{URL: &otpauth.URL{
+		Type: "totp", Issuer: "Example", Account: "alice",
+		RawSecret: "GEZDGNBVGY3TQOJQ", Algorithm: "SHA1", Digits: 6, Period: 30,
+		Extra: []otpauth.Param{{Key: aegis.GroupParam, Value: "Work"}},
+	}}}); diff != "" {
+		t.Errorf("Parse (-got, +want):\n%s", diff)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	entry := func(typ, secret string) string {
+		return `{"version": 1, "header": {"slots": null, "params": null}, "db": {"version": 3, "entries": [
+  {"type": "` + typ + `", "uuid": "x", "name": "a", "info": {"secret": "` + secret + `", "algo": "MD5", "digits": 6, "period": 10}}]}}`
+	}
+	tests := []struct {
+		name, input, want string
+	}{
+		{"NotJSON", "nonsense", "invalid vault"},
+		{"BadVersion", `{"version": 2, "header": {}, "db": {}}`, "unsupported vault version"},
+		{"BadDBVersion", `{"version": 1, "header": {}, "db": {"version": 9}}`, "unsupported database version"},
+		{"MOTP", entry("motp", "GEZDGNBVGY3TQOJQ"), `unsupported entry type "motp"`},
+		{"BadSecret", entry("totp", "not*base32"), "invalid secret"},
+		{"UnknownGroup", strings.Replace(plainVault,
+			`{"uuid": "a6a2b0a4-4f34-4e9e-8c2e-2f4f7a0a1d11"`, `{"uuid": "00000000-0000-0000-0000-000000000000"`, 1),
+			"unknown group"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := aegis.Parse([]byte(tc.input), nil)
+			if err == nil {
+				t.Fatalf("Parse: got %v, want error", entries)
+			} else if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Parse: got error %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, opts := range []*aegis.Options{nil, {Password: "correct horse", Cost: 1024}} {
+		data, err := aegis.Marshal(plainEntries, opts)
+		if err != nil {
+			t.Fatalf("Marshal: unexpected error: %v", err)
+		}
+		if opts != nil && bytes.Contains(data, []byte("GEZDGNBV")) {
+			t.Errorf("Marshal output contains a plaintext secret:\n%s", data)
+		}
+
+		entries, err := aegis.Parse(data, opts)
+		if err != nil {
+			t.Fatalf("Parse: unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(entries, plainEntries); diff != "" {
+			t.Errorf("Parse (-got, +want):\n%s", diff)
+		}
+
+		if opts != nil {
+			if _, err := aegis.Parse(data, nil); err == nil {
+				t.Error("Parse without password: got nil, want error")
+			}
+			if _, err := aegis.Parse(data, &aegis.Options{Password: "incorrect"}); !errors.Is(err, aegis.ErrPassword) {
+				t.Errorf("Parse with wrong password: got %v, want %v", err, aegis.ErrPassword)
+			}
+		}
+	}
+}
+
+func TestMarshalDefaults(t *testing.T) {
+	// Unset fields should get the default values for the entry type.
+	u := otpauth.NewSteamURL("gamer", []byte("0123456789"))
+	u.Digits, u.Period, u.Algorithm = 0, 0, ""
+	data, err := aegis.Marshal([]*aegis.Entry{{URL: u}}, nil)
+	if err != nil {
+		t.Fatalf("Marshal: unexpected error: %v", err)
+	}
+	entries, err := aegis.Parse(data, nil)
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	want := []*aegis.Entry{{URL: otpauth.NewSteamURL("gamer", []byte("0123456789"))}}
+	if diff := cmp.Diff(entries, want); diff != "" {
+		t.Errorf("Parse (-got, +want):\n%s", diff)
+	}
+
+	for _, bad := range []*otpauth.URL{
+		{Type: "motp", Account: "x", RawSecret: "GEZDGNBVGY3TQOJQ"},
+		{Type: "hotp", Account: "x", RawSecret: "GEZDGNBVGY3TQOJQ", Encoder: "steam"},
+		{Type: "totp", Account: "x", RawSecret: "GEZDGNBVGY3TQOJQ", Algorithm: "SHA-0"},
+		{Type: "totp", Account: "x", RawSecret: "not*base32"},
+	} {
+		if data, err := aegis.Marshal([]*aegis.Entry{{URL: bad}}, nil); err == nil {
+			t.Errorf("Marshal %v: got %s, want error", bad, data)
+		}
+	}
+
+	// A cost that is not a power of 2, or too large for Parse to accept, is
+	// rejected.
+	for _, cost := range []int{1000, 1 << 19} {
+		opts := &aegis.Options{Password: "x", Cost: cost}
+		if data, err := aegis.Marshal([]*aegis.Entry{{URL: u}}, opts); err == nil {
+			t.Errorf("Marshal with cost %d: got %s, want error", cost, data)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// mkvault generates the encrypted test vaults in this directory, following
+// the vault format documented by Aegis. Run with: node mkvault.js
+const crypto = require('crypto');
+function gcm(key, pt) {
+  const nonce = crypto.randomBytes(12);
+  const c = crypto.createCipheriv('aes-256-gcm', key, nonce);
+  const ct = Buffer.concat([c.update(pt), c.final()]);
+  return {ct, params: {nonce: nonce.toString('hex'), tag: c.getAuthTag().toString('hex')}};
+}
+function vault(pwBytes, n, repaired, db) {
+  const mk = crypto.randomBytes(32), salt = crypto.randomBytes(32);
+  const key = crypto.scryptSync(pwBytes, salt, 32, {N: n, r: 8, p: 1, maxmem: 256*1024*1024});
+  const s = gcm(key, mk);
+  const d = gcm(mk, Buffer.from(JSON.stringify(db)));
+  const slot = {type: 1, uuid: crypto.randomUUID(), key: s.ct.toString('hex'), key_params: s.params,
+                n: n, r: 8, p: 1, salt: salt.toString('hex'), repaired: repaired, is_backup: false};
+  return {version: 1, header: {slots: [slot], params: d.params}, db: d.ct.toString('base64')};
+}
+const db = {version: 3, entries: [
+  {type: "steam", uuid: crypto.randomUUID(), name: "gamer", issuer: "Steam", note: "", favorite: false, icon: null,
+   info: {secret: "ON2XAZLSMR2XAZLSONSWG4TFOQ", algo: "SHA1", digits: 5, period: 30},
+   groups: ["9d4c2c0e-4b9e-4a53-8d7b-2f0a5b8f6a01"]},
+  {type: "totp", uuid: crypto.randomUUID(), name: "alice@example.com", issuer: "Example", note: "", favorite: false, icon: null,
+   info: {secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", algo: "SHA1", digits: 6, period: 30}},
+], groups: [{uuid: "9d4c2c0e-4b9e-4a53-8d7b-2f0a5b8f6a01", name: "Games"}]};
+const which = process.argv[2];
+if (which === 'current') {
+  console.log(JSON.stringify(vault(Buffer.from('test', 'utf8'), 1 << 15, true, db), null, 4));
+} else {
+  // Legacy encoding of "0123456789": UTF-8 padded to floor(10 * 1.1) = 11 bytes.
+  const pw = Buffer.concat([Buffer.from('0123456789', 'utf8'), Buffer.alloc(1)]);
+  const small = {version: 3, entries: [db.entries[1]]};
+  console.log(JSON.stringify(vault(pw, 1 << 10, false, small), null, 4));
+}
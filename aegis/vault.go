@@ -0,0 +1,280 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package aegis
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	vaultVersion = 1 // the supported vault file version
+	dbVersion    = 3 // the database version written by Marshal
+
+	slotPassword = 1  // the slot type for password slots
+	keySize      = 32 // the size of the master key and slot keys
+)
+
+// The types in this file describe the JSON structure of an Aegis vault.
+
+type vaultFile struct {
+	Version int             `json:"version"`
+	Header  header          `json:"header"`
+	DB      json.RawMessage `json:"db"` // an object, or a base64 string if encrypted
+}
+
+type header struct {
+	Slots  []*slot    `json:"slots"`  // nil if not encrypted
+	Params *keyParams `json:"params"` // nil if not encrypted
+}
+
+type slot struct {
+	Type      int        `json:"type"`
+	UUID      string     `json:"uuid"`
+	Key       string     `json:"key"` // hex
+	KeyParams *keyParams `json:"key_params"`
+
+	// Password slot parameters.
+	N        int    `json:"n,omitempty"`
+	R        int    `json:"r,omitempty"`
+	P        int    `json:"p,omitempty"`
+	Salt     string `json:"salt,omitempty"`     // hex
+	Repaired bool   `json:"repaired,omitempty"` // see legacyPassword
+	IsBackup bool   `json:"is_backup,omitempty"`
+}
+
+type keyParams struct {
+	Nonce string `json:"nonce"` // hex
+	Tag   string `json:"tag"`   // hex
+}
+
+type database struct {
+	Version int      `json:"version"`
+	Entries []*entry `json:"entries"`
+	Groups  []*group `json:"groups,omitempty"`
+}
+
+type group struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+type entry struct {
+	Type     string   `json:"type"`
+	UUID     string   `json:"uuid"`
+	Name     string   `json:"name"`
+	Issuer   string   `json:"issuer"`
+	Note     string   `json:"note"`
+	Favorite bool     `json:"favorite"`
+	Icon     *string  `json:"icon"`
+	Info     info     `json:"info"`
+	Groups   []string `json:"groups,omitempty"` // group UUIDs (version 3)
+	Group    string   `json:"group,omitempty"`  // group name (version 2)
+}
+
+type info struct {
+	Secret  string  `json:"secret"` // base32
+	Algo    string  `json:"algo"`
+	Digits  int     `json:"digits"`
+	Period  int     `json:"period,omitempty"`
+	Counter *uint64 `json:"counter,omitempty"`
+	PIN     string  `json:"pin,omitempty"`
+}
+
+// Limits on the scrypt parameters of a password slot. The vault is untrusted
+// input, so these bound the memory and time used to unlock it. Aegis itself
+// uses N=2^15, r=8, p=1.
+const (
+	maxScryptN   = 1 << 20
+	maxScryptR   = 32
+	maxScryptP   = 16
+	maxScryptMem = 256 << 20 // bytes, for the 128·N·r buffer
+)
+
+// decryptVault decrypts the database of v, using the first password slot
+// that can be unlocked with password.
+func decryptVault(v *vaultFile, password string) ([]byte, error) {
+	var enc string
+	if err := json.Unmarshal(v.DB, &enc); err != nil {
+		return nil, fmt.Errorf("invalid encrypted database: %w", err)
+	}
+	ct, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted database: %w", err)
+	}
+
+	var hasSlot, legacy bool
+	for _, s := range v.Header.Slots {
+		if s.Type != slotPassword {
+			continue
+		}
+		hasSlot = true
+		if password == "" {
+			return nil, errors.New("vault is encrypted with a password")
+		}
+		mk, err := s.unlock([]byte(password))
+		if errors.Is(err, ErrPassword) && !s.Repaired {
+			legacy = true
+			mk, err = s.unlock(legacyPassword(password))
+		}
+		if errors.Is(err, ErrPassword) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		db, err := openGCM(mk, ct, v.Header.Params)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting database: %w", err)
+		}
+		return db, nil
+	}
+	if !hasSlot {
+		return nil, errors.New("vault has no password slots")
+	} else if legacy {
+		return nil, fmt.Errorf("%w (the vault has a slot created by an old version of Aegis; "+
+			"changing the password in Aegis and exporting again may help)", ErrPassword)
+	}
+	return nil, ErrPassword
+}
+
+// ErrPassword is reported by Parse when the password does not unlock any of
+// the password slots of an encrypted vault.
+var ErrPassword = errors.New("incorrect password")
+
+// checkScrypt reports an error if the scrypt parameters n, r, and p exceed the
+// limits accepted for a password slot.
+func checkScrypt(n, r, p int) error {
+	if n > maxScryptN || r > maxScryptR || p > maxScryptP || 128*int64(n)*int64(r) > maxScryptMem {
+		return fmt.Errorf("scrypt parameters (N=%d, r=%d, p=%d) exceed limits", n, r, p)
+	}
+	return nil
+}
+
+// unlock decrypts the master key from a password slot.
+func (s *slot) unlock(password []byte) ([]byte, error) {
+	if err := checkScrypt(s.N, s.R, s.P); err != nil {
+		return nil, fmt.Errorf("slot %w", err)
+	}
+	salt, err := hex.DecodeString(s.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid slot salt: %w", err)
+	}
+	key, err := scrypt.Key(password, salt, s.N, s.R, s.P, keySize)
+	if err != nil {
+		return nil, err
+	}
+	ct, err := hex.DecodeString(s.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid slot key: %w", err)
+	}
+	mk, err := openGCM(key, ct, s.KeyParams)
+	if err != nil {
+		return nil, ErrPassword
+	}
+	return mk, nil
+}
+
+// legacyPassword returns the bytes used as the password by slots that are not
+// marked as repaired. Older versions of Aegis used the whole buffer allocated
+// by Java's CharsetEncoder.encode, so the UTF-8 encoding of the password is
+// followed by zero bytes up to the capacity of that buffer. The encoder starts
+// with 1.1 bytes per UTF-16 code unit, and grows the buffer to 2n+1 bytes
+// whenever it overflows.
+func legacyPassword(password string) []byte {
+	enc := []byte(password)
+	n := int(float32(len(utf16.Encode([]rune(password)))) * 1.1)
+	for n < len(enc) {
+		n = 2*n + 1
+	}
+	return append(enc, make([]byte, n-len(enc))...)
+}
+
+// encryptVault encrypts the database of v with a new master key, and adds a
+// password slot for the key.
+func encryptVault(v *vaultFile, password string, cost int) error {
+	// Do not write a slot that Parse would refuse to unlock.
+	if err := checkScrypt(cost, 8, 1); err != nil {
+		return fmt.Errorf("cost %d: %w", cost, err)
+	}
+	mk := make([]byte, keySize)
+	rand.Read(mk)
+	salt := make([]byte, 32)
+	rand.Read(salt)
+	s := &slot{
+		Type: slotPassword,
+		UUID: newUUID(),
+		N:    cost, R: 8, P: 1,
+		Salt:     hex.EncodeToString(salt),
+		Repaired: true,
+	}
+	key, err := scrypt.Key([]byte(password), salt, s.N, s.R, s.P, keySize)
+	if err != nil {
+		return err
+	}
+	ct, params := sealGCM(key, mk)
+	s.Key, s.KeyParams = hex.EncodeToString(ct), params
+
+	dbct, params := sealGCM(mk, v.DB)
+	db, err := json.Marshal(base64.StdEncoding.EncodeToString(dbct))
+	if err != nil {
+		return err
+	}
+	v.DB = db
+	v.Header = header{Slots: []*slot{s}, Params: params}
+	return nil
+}
+
+// openGCM decrypts ciphertext with AES-GCM, using the nonce and the
+// authentication tag from p.
+func openGCM(key, ciphertext []byte, p *keyParams) ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("missing key parameters")
+	}
+	nonce, err := hex.DecodeString(p.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	tag, err := hex.DecodeString(p.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag: %w", err)
+	}
+	aead, err := newGCM(key, len(nonce))
+	if err != nil {
+		return nil, err
+	}
+	if len(tag) != aead.Overhead() {
+		return nil, fmt.Errorf("tag has length %d, want %d", len(tag), aead.Overhead())
+	}
+	return aead.Open(nil, nonce, append(ciphertext[:len(ciphertext):len(ciphertext)], tag...), nil)
+}
+
+// sealGCM encrypts plaintext with AES-GCM under a random nonce. It returns
+// the ciphertext and the parameters needed to decrypt it.
+func sealGCM(key, plaintext []byte) ([]byte, *keyParams) {
+	aead, err := newGCM(key, 12)
+	if err != nil {
+		panic(err) // the key is generated by the caller
+	}
+	nonce := make([]byte, aead.NonceSize())
+	rand.Read(nonce)
+	out := aead.Seal(nil, nonce, plaintext, nil)
+	ct, tag := out[:len(out)-aead.Overhead()], out[len(out)-aead.Overhead():]
+	return ct, &keyParams{Nonce: hex.EncodeToString(nonce), Tag: hex.EncodeToString(tag)}
+}
+
+func newGCM(key []byte, nonceSize int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithNonceSize(block, nonceSize)
+}
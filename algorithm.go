@@ -0,0 +1,94 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// An Algorithm identifies the hash algorithm used to generate codes.
+// The zero value is SHA1, the default for HOTP and TOTP.
+type Algorithm int
+
+// Supported hash algorithms.
+const (
+	SHA1     Algorithm = iota // RFC 3174; the default
+	SHA256                    // FIPS 180-4
+	SHA512                    // FIPS 180-4
+	MD5                       // RFC 1321; used by some legacy tokens
+	SHA3_256                  // FIPS 202
+	SHA3_512                  // FIPS 202
+)
+
+var algorithms = [...]struct {
+	name string
+	cons func() hash.Hash
+}{
+	SHA1:     {"SHA1", sha1.New},
+	SHA256:   {"SHA256", sha256.New},
+	SHA512:   {"SHA512", sha512.New},
+	MD5:      {"MD5", md5.New},
+	SHA3_256: {"SHA3-256", func() hash.Hash { return sha3.New256() }},
+	SHA3_512: {"SHA3-512", func() hash.Hash { return sha3.New512() }},
+}
+
+// ParseAlgorithm parses the name of an algorithm, such as "SHA256". Case is
+// not significant, and hyphens and underscores are ignored, so "sha-256" is
+// equivalent to "SHA256".
+func ParseAlgorithm(s string) (Algorithm, error) {
+	norm := strings.NewReplacer("-", "", "_", "").Replace(strings.ToUpper(s))
+	for i, a := range algorithms {
+		if strings.ReplaceAll(a.name, "-", "") == norm {
+			return Algorithm(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown algorithm %q", s)
+}
+
+// Valid reports whether a is a known algorithm.
+func (a Algorithm) Valid() bool { return a >= 0 && int(a) < len(algorithms) }
+
+// String returns the canonical name of a, such as "SHA256".
+func (a Algorithm) String() string {
+	if a.Valid() {
+		return algorithms[a].name
+	}
+	return "Algorithm(" + strconv.Itoa(int(a)) + ")"
+}
+
+// New constructs a new hash for algorithm a.
+// It panics if a is not a known algorithm.
+func (a Algorithm) New() hash.Hash {
+	if !a.Valid() {
+		panic(fmt.Sprintf("unknown algorithm %d", int(a)))
+	}
+	return algorithms[a].cons()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// It reports an error if a is not a known algorithm.
+func (a Algorithm) MarshalText() ([]byte, error) {
+	if !a.Valid() {
+		return nil, fmt.Errorf("unknown algorithm %d", int(a))
+	}
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// It accepts any name accepted by ParseAlgorithm.
+func (a *Algorithm) UnmarshalText(text []byte) error {
+	v, err := ParseAlgorithm(string(text))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/creachadair/otp"
+)
+
+func TestAlgorithm(t *testing.T) {
+	tests := []struct {
+		alg  otp.Algorithm
+		name string
+		size int
+	}{
+		{otp.SHA1, "SHA1", 20},
+		{otp.SHA256, "SHA256", 32},
+		{otp.SHA512, "SHA512", 64},
+		{otp.MD5, "MD5", 16},
+		{otp.SHA3_256, "SHA3-256", 32},
+		{otp.SHA3_512, "SHA3-512", 64},
+	}
+	for _, tc := range tests {
+		if got := tc.alg.String(); got != tc.name {
+			t.Errorf("String: got %q, want %q", got, tc.name)
+		}
+		if got := tc.alg.New().Size(); got != tc.size {
+			t.Errorf("%v size: got %d, want %d", tc.alg, got, tc.size)
+		}
+		got, err := otp.ParseAlgorithm(tc.name)
+		if err != nil || got != tc.alg {
+			t.Errorf("ParseAlgorithm(%q): got (%v, %v), want %v", tc.name, got, err, tc.alg)
+		}
+	}
+
+	for _, s := range []string{"sha-256", "Sha256", "SHA_256", "sha3_512", "sha3512"} {
+		if _, err := otp.ParseAlgorithm(s); err != nil {
+			t.Errorf("ParseAlgorithm(%q): unexpected error: %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "SHA", "SHA384", "SHA3-384", "CRC32"} {
+		if got, err := otp.ParseAlgorithm(s); err == nil {
+			t.Errorf("ParseAlgorithm(%q): got %v, want error", s, got)
+		}
+	}
+
+	if bad := otp.Algorithm(99); bad.Valid() {
+		t.Errorf("Algorithm %v is unexpectedly valid", bad)
+	} else if got := bad.String(); got != "Algorithm(99)" {
+		t.Errorf("String: got %q, want %q", got, "Algorithm(99)")
+	}
+}
+
+func TestAlgorithmJSON(t *testing.T) {
+	type wrapper struct {
+		Algo otp.Algorithm `json:"algo"`
+	}
+	data, err := json.Marshal(wrapper{Algo: otp.SHA512})
+	if err != nil {
+		t.Fatalf("Marshal: unexpected error: %v", err)
+	}
+	if got, want := string(data), `{"algo":"SHA512"}`; got != want {
+		t.Errorf("Marshal: got %s, want %s", got, want)
+	}
+	var w wrapper
+	if err := json.Unmarshal([]byte(`{"algo":"sha-256"}`), &w); err != nil {
+		t.Fatalf("Unmarshal: unexpected error: %v", err)
+	} else if w.Algo != otp.SHA256 {
+		t.Errorf("Unmarshal: got %v, want %v", w.Algo, otp.SHA256)
+	}
+	if _, err := json.Marshal(wrapper{Algo: -1}); err == nil {
+		t.Error("Marshal of invalid algorithm: got nil, want error")
+	}
+}
+
+func TestConfig_Algorithm(t *testing.T) {
+	algs := map[string]otp.Algorithm{"SHA1": otp.SHA1, "SHA256": otp.SHA256, "SHA512": otp.SHA512}
+	for _, tc := range testVectors {
+		t.Run(fmt.Sprintf("%s-%d", tc.alg, tc.seconds), func(t *testing.T) {
+			cfg := otp.Config{
+				Key:       testHash[tc.alg].key,
+				Algorithm: algs[tc.alg],
+				Digits:    len(tc.want),
+			}
+			if got := cfg.TOTPAt(time.Unix(int64(tc.seconds), 0)); got != tc.want {
+				t.Errorf("TOTPAt(%d): got %q, want %q", tc.seconds, got, tc.want)
+			}
+		})
+	}
+}
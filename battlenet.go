@@ -0,0 +1,45 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp
+
+import (
+	"crypto/sha1"
+	"strings"
+)
+
+// battleNetRestoreAlphabet is the alphabet used for Battle.net restore codes.
+// It omits the letters I, L, O, and S, which are easily confused with digits.
+const battleNetRestoreAlphabet = "0123456789ABCDEFGHJKMNPQRTUVWXYZ"
+
+// BattleNetRestoreCode computes the 10-character restore code for a
+// Battle.net authenticator with the given serial number (e.g.,
+// "US-1234-5678-9012") and secret key. Case and hyphens in the serial are not
+// significant.
+//
+// The code is derived from the last 10 bytes of the SHA-1 digest of the
+// normalized serial followed by the key, mapping the low-order 5 bits of each
+// byte to a letter of the alphabet "0123456789ABCDEFGHJKMNPQRTUVWXYZ".
+func BattleNetRestoreCode(serial string, key []byte) string {
+	h := sha1.New()
+	h.Write([]byte(normalizeBattleNet(serial)))
+	h.Write(key)
+	sum := h.Sum(nil)
+
+	out := make([]byte, 10)
+	for i, b := range sum[len(sum)-len(out):] {
+		out[i] = battleNetRestoreAlphabet[b&0x1f]
+	}
+	return string(out)
+}
+
+// VerifyBattleNetRestoreCode reports whether code is the restore code for the
+// given serial number and secret key. Case, spaces, and hyphens in the code are
+// not significant. Codes are compared in constant time.
+func VerifyBattleNetRestoreCode(serial string, key []byte, code string) bool {
+	return equalCode(BattleNetRestoreCode(serial, key), normalizeBattleNet(code))
+}
+
+// normalizeBattleNet converts s to uppercase and removes hyphens and spaces.
+func normalizeBattleNet(s string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(s))
+}
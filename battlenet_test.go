@@ -0,0 +1,38 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp_test
+
+import (
+	"testing"
+
+	"github.com/creachadair/otp"
+)
+
+func TestBattleNetRestoreCode(t *testing.T) {
+	// Reference value from the python-bna test suite, which passes the secret
+	// as these 40 bytes.
+	const want = "4B91NQCYQ3"
+	key := []byte("88aaface48291e09dc1ece9c2aa44d839983a7ff")
+
+	// Case and hyphens in the serial are not significant.
+	for _, serial := range []string{"US120910711868", "US-1209-1071-1868", "us-1209-1071-1868"} {
+		if got := otp.BattleNetRestoreCode(serial, key); got != want {
+			t.Errorf("BattleNetRestoreCode(%q): got %q, want %q", serial, got, want)
+		}
+	}
+
+	// Case, spaces, and hyphens in the code are not significant.
+	for _, code := range []string{want, "4b91nqcyq3", "4B91-NQCY-Q3", "4B91 NQCY Q3"} {
+		if !otp.VerifyBattleNetRestoreCode("US-1209-1071-1868", key, code) {
+			t.Errorf("VerifyBattleNetRestoreCode(%q): got false, want true", code)
+		}
+	}
+	for _, code := range []string{"", "4B91NQCYQ", "4B91NQCYQ4", "3Q YCQN19B4"} {
+		if otp.VerifyBattleNetRestoreCode("US-1209-1071-1868", key, code) {
+			t.Errorf("VerifyBattleNetRestoreCode(%q): got true, want false", code)
+		}
+	}
+	if otp.VerifyBattleNetRestoreCode("US-1209-1071-1869", key, want) {
+		t.Errorf("VerifyBattleNetRestoreCode(wrong serial): got true, want false")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/creachadair/otp"
 )
@@ -42,8 +43,32 @@ func Example() {
 	// TOTP 86761489
 }
 
+func ExampleParseAlgorithm() {
+	// Algorithm names are often spelled differently by different tools.
+	alg, err := otp.ParseAlgorithm("sha-256")
+	if err != nil {
+		log.Fatalf("Parsing algorithm: %v", err)
+	}
+	cfg, err := otp.Config{
+		Algorithm: alg, // equivalent to Hash: sha256.New
+		Digits:    8,
+		TimeStep:  fixedTime(1),
+	}.WithKey("MFYH A3DF EB2G C4TU")
+	if err != nil {
+		log.Fatalf("Parsing key: %v", err)
+	}
+
+	fmt.Println(alg)
+	fmt.Println("TOTP", cfg.TOTP())
+	// Output:
+	// SHA256
+	// TOTP 86761489
+}
+
 func ExampleConfig_customFormat() {
-	// Use settings compatible with Steam Guard: 5 characters and a custom alphabet.
+	// Use 5 characters from a custom alphabet. FormatAlphabet expands the code
+	// most significant first, so this does not produce Steam Guard codes even
+	// with the Steam alphabet; use SteamConfig or FormatSteam for those.
 	cfg, err := otp.Config{
 		Digits:   5,
 		Format:   otp.FormatAlphabet("23456789BCDFGHJKMNPQRTVWXY"),
@@ -58,6 +83,15 @@ func ExampleConfig_customFormat() {
 	// FKNK3
 }
 
+func ExampleSteamConfig() {
+	cfg := otp.SteamConfig()
+	cfg.Key = "superdupersecret"
+
+	fmt.Println(cfg.TOTPAt(time.Unix(3000030, 0)))
+	// Output:
+	// YRGQJ
+}
+
 func ExampleConfig_rawFormat() {
 	// The default formatting functions use the RFC 4226 truncation rules, but a
 	// custom formatter may do whatever it likes with the HMAC value.
@@ -0,0 +1,6 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp
+
+// UnregisterPreset exposes unregisterPreset to tests.
+var UnregisterPreset = unregisterPreset
@@ -8,6 +8,8 @@ require github.com/creachadair/mds v0.30.4
 
 require github.com/creachadair/wirepb v0.0.0-20260702150408-a42f1574e053
 
+require golang.org/x/crypto v0.54.0
+
 require (
 	golang.org/x/exp/typeparams v0.0.0-20231108232855-2478ac86f678 // indirect
 	golang.org/x/mod v0.31.0 // indirect
@@ -4,7 +4,9 @@ package otp
 
 import (
 	"encoding/hex"
+	"strconv"
 	"testing"
+	"time"
 )
 
 type testCase struct {
@@ -79,3 +81,27 @@ func TestConfig_TOTP(t *testing.T) {
 		test.Run(t, cfg, func(uint64) string { return cfg.TOTP() })
 	}
 }
+
+func TestThrottle_Evict(t *testing.T) {
+	now := time.Unix(1000, 0)
+	th := &Throttle{ResetAfter: time.Minute, Now: func() time.Time { return now }}
+	for i := range 1000 {
+		th.Failure(strconv.Itoa(i))
+	}
+	if n := len(th.state); n != 1000 {
+		t.Fatalf("Got %d subjects, want 1000", n)
+	}
+
+	// Once the failures have expired, the stale subjects are discarded as new
+	// subjects are added.
+	now = now.Add(time.Minute)
+	for i := range 1000 {
+		th.Failure("new" + strconv.Itoa(i))
+	}
+	if n := len(th.state); n != 1000 {
+		t.Errorf("Got %d subjects, want 1000", n)
+	}
+	if _, ok := th.state["0"]; ok {
+		t.Error("Stale subject was not discarded")
+	}
+}
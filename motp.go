@@ -0,0 +1,65 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MOTP generates and verifies Mobile-OTP (mOTP) codes. An mOTP code is the
+// first 6 hexadecimal digits of the MD5 digest of the number of 10-second
+// intervals since the Unix epoch (in decimal), the secret, and the PIN.
+//
+// See https://motp.sourceforge.net/
+type MOTP struct {
+	Secret string // the token secret, typically 16 hexadecimal digits
+	PIN    string // the user's PIN, typically 4 digits
+
+	// TimeStep, if non-nil, returns the current time step, the number of
+	// 10-second intervals since the Unix epoch. If nil, the step is computed
+	// from time.Now.
+	TimeStep func() uint64
+}
+
+// motpPeriod is the duration of an mOTP time step.
+const motpPeriod = 10 * time.Second
+
+// Code returns the mOTP code for the specified time step.
+func (m MOTP) Code(step uint64) string {
+	sum := md5.Sum([]byte(strconv.FormatUint(step, 10) + m.Secret + m.PIN))
+	return hex.EncodeToString(sum[:3])
+}
+
+// Now returns the mOTP code for the current time step.
+func (m MOTP) Now() string { return m.Code(m.step()) }
+
+// CodeAt returns the mOTP code for the time step containing t.
+// Times before the Unix epoch are treated as step 0.
+func (m MOTP) CodeAt(t time.Time) string { return m.Code(motpStep(t)) }
+
+// Verify reports whether code is a valid mOTP code for the current time step,
+// or for one of the steps permitted by the Before and After settings of opts,
+// which are measured in 10-second steps. If so, it also returns the offset of
+// the matching step relative to the current step, as for [Config.VerifyTOTP].
+// Case is not significant. Codes are compared in constant time.
+func (m MOTP) Verify(code string, opts *VerifyOptions) (int, bool) {
+	return verifyWindow(m.Code, strings.ToLower(code), m.step(), opts.before(), opts.after())
+}
+
+func (m MOTP) step() uint64 {
+	if m.TimeStep != nil {
+		return m.TimeStep()
+	}
+	return motpStep(time.Now())
+}
+
+func motpStep(t time.Time) uint64 {
+	if u := t.Unix(); u > 0 {
+		return uint64(u) / uint64(motpPeriod/time.Second)
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/creachadair/otp"
+)
+
+func TestMOTP(t *testing.T) {
+	tests := []struct {
+		secret, pin string
+		unix        int64
+		want        string
+	}{
+		{"1234567890abcdef", "1234", 1700000000, "660af9"},
+		{"1234567890abcdef", "1234", 1700000005, "660af9"},
+		{"1234567890abcdef", "1234", 1700000010, "a42821"},
+		{"0123456789abcdef", "0000", 1234567890, "93bc6a"},
+		{"0123456789abcdef", "0000", 0, "07b7ff"},
+		{"0123456789abcdef", "0000", -100, "07b7ff"},
+	}
+	for _, tc := range tests {
+		m := otp.MOTP{Secret: tc.secret, PIN: tc.pin}
+		if got := m.CodeAt(time.Unix(tc.unix, 0)); got != tc.want {
+			t.Errorf("CodeAt(%d): got %q, want %q", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestMOTP_Verify(t *testing.T) {
+	m := otp.MOTP{
+		Secret:   "1234567890abcdef",
+		PIN:      "1234",
+		TimeStep: fixedTime(170000001),
+	}
+	if got, want := m.Now(), m.Code(170000001); got != want {
+		t.Errorf("Now: got %q, want %q", got, want)
+	}
+	// Standard mOTP servers accept codes up to 3 minutes either side.
+	opts := &otp.VerifyOptions{Before: 18, After: 18}
+	tests := []struct {
+		step uint64
+		opts *otp.VerifyOptions
+		want int
+		ok   bool
+	}{
+		{170000001, nil, 0, true},
+		{170000000, nil, 0, false},
+		{170000000, opts, -1, true},
+		{170000019, opts, 18, true},
+		{170000020, opts, 0, false},
+		{169999983, opts, -18, true},
+		{169999982, opts, 0, false},
+	}
+	for _, tc := range tests {
+		code := m.Code(tc.step)
+		got, ok := m.Verify(code, tc.opts)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("Verify(%q) [step %d]: got (%d, %v), want (%d, %v)", code, tc.step, got, ok, tc.want, tc.ok)
+		}
+	}
+
+	// Case is not significant.
+	if _, ok := m.Verify("A42821", nil); !ok {
+		t.Error("Verify(A42821): got false, want true")
+	}
+}
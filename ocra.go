@@ -0,0 +1,307 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An OCRASuite describes the parameters of the OCRA challenge-response
+// algorithm specified in RFC 6287. Use ParseOCRASuite to construct a suite
+// from its string representation, e.g., "OCRA-1:HOTP-SHA256-8:QN08-PSHA1".
+//
+// See https://tools.ietf.org/html/rfc6287
+type OCRASuite struct {
+	name string // the original suite string
+
+	hash    func() hash.Hash // the HMAC hash function
+	digits  int              // 0 means no truncation
+	counter bool             // whether a counter value is included
+
+	qFormat byte // 'A' (alphanumeric), 'N' (numeric), or 'H' (hexadecimal)
+	qLen    int  // maximum question length
+
+	pinHash func() hash.Hash // if nil, no PIN is included
+	sessLen int              // if zero, no session information is included
+	step    time.Duration    // if zero, no timestamp is included
+}
+
+// String returns the string representation of the suite.
+func (s *OCRASuite) String() string { return s.name }
+
+// Digits returns the number of digits in a response, or 0 if the suite
+// specifies that responses are not truncated.
+func (s *OCRASuite) Digits() int { return s.digits }
+
+// TimeStep returns the time step used for timestamps, or 0 if the suite does
+// not include a timestamp.
+func (s *OCRASuite) TimeStep() time.Duration { return s.step }
+
+// ParseOCRASuite parses s as an OCRA suite string, with the syntax
+//
+//	OCRA-1:HOTP-<hash>-<digits>:[C-]Q<fmt><len>[-P<hash>][-S<len>][-T<step>]
+//
+// as described in RFC 6287 Section 6.
+func ParseOCRASuite(s string) (*OCRASuite, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid OCRA suite %q", s)
+	}
+	if parts[0] != "OCRA-1" {
+		return nil, fmt.Errorf("unsupported OCRA version %q", parts[0])
+	}
+	out := &OCRASuite{name: s}
+
+	// Crypto function: HOTP-<hash>-<digits>
+	cf := strings.Split(parts[1], "-")
+	if len(cf) != 3 || cf[0] != "HOTP" {
+		return nil, fmt.Errorf("invalid crypto function %q", parts[1])
+	}
+	out.hash = ocraHash(cf[1])
+	if out.hash == nil {
+		return nil, fmt.Errorf("unsupported hash %q", cf[1])
+	}
+	nd, err := strconv.Atoi(cf[2])
+	if err != nil || (nd != 0 && (nd < 4 || nd > 10)) {
+		return nil, fmt.Errorf("invalid digits %q", cf[2])
+	}
+	out.digits = nd
+
+	// Data input: [C-]QFxx[-PH][-Snnn][-TG]
+	di := strings.Split(parts[2], "-")
+	if di[0] == "C" {
+		out.counter = true
+		di = di[1:]
+	}
+	if len(di) == 0 || len(di[0]) != 4 || di[0][0] != 'Q' {
+		return nil, fmt.Errorf("invalid question format in %q", parts[2])
+	}
+	switch f := di[0][1]; f {
+	case 'A', 'N', 'H':
+		out.qFormat = f
+	default:
+		return nil, fmt.Errorf("invalid question format %q", di[0])
+	}
+	out.qLen, err = strconv.Atoi(di[0][2:])
+	if err != nil || out.qLen < 4 || out.qLen > 64 {
+		return nil, fmt.Errorf("invalid question length %q", di[0])
+	}
+
+	for _, opt := range di[1:] {
+		switch {
+		case opt == "":
+			return nil, fmt.Errorf("invalid data input %q", parts[2])
+		case opt[0] == 'P' && out.pinHash == nil && out.sessLen == 0 && out.step == 0:
+			out.pinHash = ocraHash(opt[1:])
+			if out.pinHash == nil {
+				return nil, fmt.Errorf("unsupported PIN hash %q", opt)
+			}
+		case opt[0] == 'S' && out.sessLen == 0 && out.step == 0:
+			n, err := strconv.Atoi(opt[1:])
+			if err != nil || len(opt) != 4 || n <= 0 {
+				return nil, fmt.Errorf("invalid session length %q", opt)
+			}
+			out.sessLen = n
+		case opt[0] == 'T' && out.step == 0:
+			out.step, err = parseOCRAStep(opt[1:])
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid data input %q", opt)
+		}
+	}
+	return out, nil
+}
+
+func ocraHash(name string) func() hash.Hash {
+	switch name {
+	case "SHA1":
+		return sha1.New
+	case "SHA256":
+		return sha256.New
+	case "SHA512":
+		return sha512.New
+	}
+	return nil
+}
+
+func parseOCRAStep(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, errors.New("empty time step")
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid time step %q", s)
+	}
+	switch s[len(s)-1] {
+	case 'S':
+		if n > 59 {
+			break
+		}
+		return time.Duration(n) * time.Second, nil
+	case 'M':
+		if n > 59 {
+			break
+		}
+		return time.Duration(n) * time.Minute, nil
+	case 'H':
+		if n > 48 {
+			break
+		}
+		return time.Duration(n) * time.Hour, nil
+	}
+	return 0, fmt.Errorf("invalid time step %q", s)
+}
+
+// OCRAInput holds the data inputs for an OCRA computation. Which fields are
+// used is determined by the suite; fields not used by the suite are ignored.
+type OCRAInput struct {
+	// Counter is the counter value, used if the suite specifies "C".
+	Counter uint64
+
+	// Question is the challenge, in the format specified by the suite:
+	// decimal digits (N), hexadecimal digits (H), or arbitrary text (A).
+	// For mutual challenge-response, it is the concatenation of the client
+	// and server challenges.
+	Question string
+
+	// PIN is the PIN or password, which is hashed as specified by the suite.
+	// If PINHash is set, PIN is ignored.
+	PIN string
+
+	// PINHash is the precomputed hash of the PIN.
+	PINHash []byte
+
+	// Session is the session information, used if the suite specifies "S".
+	// It must not be longer than the length specified by the suite.
+	Session []byte
+
+	// Time is the time used to compute the timestamp, if the suite specifies
+	// "T". If zero, the current time is used.
+	Time time.Time
+}
+
+// Generate computes the OCRA response for the specified key and inputs.
+// It reports an error if the inputs are not valid for the suite.
+func (s *OCRASuite) Generate(key []byte, in OCRAInput) (string, error) {
+	msg, err := s.message(in)
+	if err != nil {
+		return "", err
+	}
+	h := hmac.New(s.hash, key)
+	h.Write(msg)
+	sum := h.Sum(nil)
+	if s.digits == 0 {
+		return hex.EncodeToString(sum), nil
+	}
+	return formatDecimal(sum, s.digits), nil
+}
+
+// Verify reports whether code is the correct OCRA response for the specified
+// key and inputs. Codes are compared in constant time.
+func (s *OCRASuite) Verify(key []byte, in OCRAInput, code string) bool {
+	want, err := s.Generate(key, in)
+	return err == nil && equalCode(want, code)
+}
+
+// message constructs the input to the HMAC for the specified inputs, as
+// described in RFC 6287 Section 5.1.
+func (s *OCRASuite) message(in OCRAInput) ([]byte, error) {
+	buf := append([]byte(s.name), 0)
+	if s.counter {
+		buf = binary.BigEndian.AppendUint64(buf, in.Counter)
+	}
+
+	q, err := s.question(in.Question)
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, q...)
+
+	if s.pinHash != nil {
+		ph := in.PINHash
+		if ph == nil {
+			h := s.pinHash()
+			h.Write([]byte(in.PIN))
+			ph = h.Sum(nil)
+		} else if len(ph) != s.pinHash().Size() {
+			return nil, fmt.Errorf("invalid PIN hash length %d", len(ph))
+		}
+		buf = append(buf, ph...)
+	}
+	if s.sessLen > 0 {
+		if len(in.Session) > s.sessLen {
+			return nil, fmt.Errorf("session information too long (%d > %d)", len(in.Session), s.sessLen)
+		}
+		buf = append(buf, make([]byte, s.sessLen-len(in.Session))...) // left-pad
+		buf = append(buf, in.Session...)
+	}
+	if s.step > 0 {
+		t := in.Time
+		if t.IsZero() {
+			t = time.Now()
+		}
+		buf = binary.BigEndian.AppendUint64(buf, uint64(t.Unix())/uint64(s.step/time.Second))
+	}
+	return buf, nil
+}
+
+// question encodes q per the suite question format, right-padded with zeroes
+// to 128 bytes.
+//
+// The question length in the suite is not enforced as a maximum, since in
+// mutual challenge-response mode (RFC 6287 Section 7.3) the question is the
+// concatenation of the client and server challenges.
+func (s *OCRASuite) question(q string) ([]byte, error) {
+	const qBytes = 128
+
+	if len(q) < 4 {
+		return nil, fmt.Errorf("question length %d is too short", len(q))
+	}
+	var raw []byte
+	switch s.qFormat {
+	case 'A':
+		raw = []byte(q)
+	case 'N':
+		v, ok := new(big.Int).SetString(q, 10)
+		if !ok || v.Sign() < 0 {
+			return nil, fmt.Errorf("invalid numeric question %q", q)
+		}
+		// The numeric value is rendered in hexadecimal, and then the digits
+		// are packed left-aligned, so an odd digit count leaves a zero nybble.
+		hx := v.Text(16)
+		if len(hx)%2 != 0 {
+			hx += "0"
+		}
+		raw, _ = hex.DecodeString(hx)
+	case 'H':
+		hx := q
+		if len(hx)%2 != 0 {
+			hx += "0"
+		}
+		var err error
+		raw, err = hex.DecodeString(hx)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hexadecimal question %q", q)
+		}
+	}
+	if len(raw) > qBytes {
+		return nil, fmt.Errorf("question length %d is too long", len(q))
+	}
+	out := make([]byte, qBytes)
+	copy(out, raw)
+	return out, nil
+}
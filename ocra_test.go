@@ -0,0 +1,203 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creachadair/otp"
+)
+
+// Test keys and inputs from RFC 6287 Appendix C.
+const (
+	ocraKey20 = "12345678901234567890"
+	ocraKey32 = "12345678901234567890123456789012"
+	ocraKey64 = "1234567890123456789012345678901234567890123456789012345678901234"
+)
+
+// ocraTime is the timestamp used by the RFC 6287 test vectors, 0x132d0b6
+// minutes after the Unix epoch.
+var ocraTime = time.Unix(0x132d0b6*60, 0)
+
+func TestOCRAVectors(t *testing.T) {
+	type vector struct {
+		key  string
+		in   otp.OCRAInput
+		want string
+	}
+	qs := func(d int) string { return strings.Repeat(fmt.Sprint(d), 8) }
+
+	tests := map[string][]vector{
+		// One-way challenge-response (RFC 6287 Appendix C.1).
+		"OCRA-1:HOTP-SHA1-6:QN08": {
+			{ocraKey20, otp.OCRAInput{Question: qs(0)}, "237653"},
+			{ocraKey20, otp.OCRAInput{Question: qs(1)}, "243178"},
+			{ocraKey20, otp.OCRAInput{Question: qs(2)}, "653583"},
+			{ocraKey20, otp.OCRAInput{Question: qs(3)}, "740991"},
+			{ocraKey20, otp.OCRAInput{Question: qs(4)}, "608993"},
+			{ocraKey20, otp.OCRAInput{Question: qs(5)}, "388898"},
+			{ocraKey20, otp.OCRAInput{Question: qs(6)}, "816933"},
+			{ocraKey20, otp.OCRAInput{Question: qs(7)}, "224598"},
+			{ocraKey20, otp.OCRAInput{Question: qs(8)}, "750600"},
+			{ocraKey20, otp.OCRAInput{Question: qs(9)}, "294470"},
+		},
+		"OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1": {
+			{ocraKey32, otp.OCRAInput{Counter: 0, Question: "12345678", PIN: "1234"}, "65347737"},
+			{ocraKey32, otp.OCRAInput{Counter: 1, Question: "12345678", PIN: "1234"}, "86775851"},
+			{ocraKey32, otp.OCRAInput{Counter: 2, Question: "12345678", PIN: "1234"}, "78192410"},
+			{ocraKey32, otp.OCRAInput{Counter: 3, Question: "12345678", PIN: "1234"}, "71565254"},
+			{ocraKey32, otp.OCRAInput{Counter: 4, Question: "12345678", PIN: "1234"}, "10104329"},
+			{ocraKey32, otp.OCRAInput{Counter: 5, Question: "12345678", PIN: "1234"}, "65983500"},
+			{ocraKey32, otp.OCRAInput{Counter: 6, Question: "12345678", PIN: "1234"}, "70069104"},
+			{ocraKey32, otp.OCRAInput{Counter: 7, Question: "12345678", PIN: "1234"}, "91771096"},
+			{ocraKey32, otp.OCRAInput{Counter: 8, Question: "12345678", PIN: "1234"}, "75011558"},
+			{ocraKey32, otp.OCRAInput{Counter: 9, Question: "12345678", PIN: "1234"}, "08522129"},
+		},
+		"OCRA-1:HOTP-SHA256-8:QN08-PSHA1": {
+			{ocraKey32, otp.OCRAInput{Question: qs(0), PIN: "1234"}, "83238735"},
+			{ocraKey32, otp.OCRAInput{Question: qs(1), PIN: "1234"}, "01501458"},
+			{ocraKey32, otp.OCRAInput{Question: qs(2), PIN: "1234"}, "17957585"},
+			{ocraKey32, otp.OCRAInput{Question: qs(3), PIN: "1234"}, "86776967"},
+			{ocraKey32, otp.OCRAInput{Question: qs(4), PIN: "1234"}, "86807031"},
+		},
+		"OCRA-1:HOTP-SHA512-8:C-QN08": {
+			{ocraKey64, otp.OCRAInput{Counter: 0, Question: qs(0)}, "07016083"},
+			{ocraKey64, otp.OCRAInput{Counter: 1, Question: qs(1)}, "63947962"},
+			{ocraKey64, otp.OCRAInput{Counter: 2, Question: qs(2)}, "70123924"},
+			{ocraKey64, otp.OCRAInput{Counter: 3, Question: qs(3)}, "25341727"},
+			{ocraKey64, otp.OCRAInput{Counter: 4, Question: qs(4)}, "33203315"},
+			{ocraKey64, otp.OCRAInput{Counter: 5, Question: qs(5)}, "34205738"},
+			{ocraKey64, otp.OCRAInput{Counter: 6, Question: qs(6)}, "44343969"},
+			{ocraKey64, otp.OCRAInput{Counter: 7, Question: qs(7)}, "51946085"},
+			{ocraKey64, otp.OCRAInput{Counter: 8, Question: qs(8)}, "20403879"},
+			{ocraKey64, otp.OCRAInput{Counter: 9, Question: qs(9)}, "31409299"},
+		},
+		"OCRA-1:HOTP-SHA512-8:QN08-T1M": {
+			{ocraKey64, otp.OCRAInput{Question: qs(0), Time: ocraTime}, "95209754"},
+			{ocraKey64, otp.OCRAInput{Question: qs(1), Time: ocraTime}, "55907591"},
+			{ocraKey64, otp.OCRAInput{Question: qs(2), Time: ocraTime}, "22048402"},
+			{ocraKey64, otp.OCRAInput{Question: qs(3), Time: ocraTime}, "24218844"},
+			{ocraKey64, otp.OCRAInput{Question: qs(4), Time: ocraTime}, "36209546"},
+		},
+
+		// Mutual challenge-response (RFC 6287 Appendix C.2).
+		"OCRA-1:HOTP-SHA256-8:QA08": {
+			{ocraKey32, otp.OCRAInput{Question: "CLI22220SRV11110"}, "28247970"},
+			{ocraKey32, otp.OCRAInput{Question: "CLI22221SRV11111"}, "01984843"},
+			{ocraKey32, otp.OCRAInput{Question: "CLI22222SRV11112"}, "65387857"},
+			{ocraKey32, otp.OCRAInput{Question: "CLI22223SRV11113"}, "03351211"},
+			{ocraKey32, otp.OCRAInput{Question: "CLI22224SRV11114"}, "83412541"},
+			// Plain signature (RFC 6287 Appendix C.3).
+			{ocraKey32, otp.OCRAInput{Question: "SIG10000"}, "53095496"},
+			{ocraKey32, otp.OCRAInput{Question: "SIG11000"}, "04110475"},
+			{ocraKey32, otp.OCRAInput{Question: "SIG12000"}, "31331128"},
+			{ocraKey32, otp.OCRAInput{Question: "SIG13000"}, "76028668"},
+			{ocraKey32, otp.OCRAInput{Question: "SIG14000"}, "46554205"},
+		},
+		"OCRA-1:HOTP-SHA512-8:QA08-PSHA1": {
+			{ocraKey64, otp.OCRAInput{Question: "SRV11110CLI22220", PIN: "1234"}, "18806276"},
+			{ocraKey64, otp.OCRAInput{Question: "SRV11111CLI22221", PIN: "1234"}, "70020315"},
+			{ocraKey64, otp.OCRAInput{Question: "SRV11112CLI22222", PIN: "1234"}, "01600026"},
+			{ocraKey64, otp.OCRAInput{Question: "SRV11113CLI22223", PIN: "1234"}, "18951020"},
+			{ocraKey64, otp.OCRAInput{Question: "SRV11114CLI22224", PIN: "1234"}, "32528969"},
+		},
+		"OCRA-1:HOTP-SHA512-8:QA10-T1M": {
+			{ocraKey64, otp.OCRAInput{Question: "SIG1000000", Time: ocraTime}, "77537423"},
+			{ocraKey64, otp.OCRAInput{Question: "SIG1100000", Time: ocraTime}, "31970405"},
+			{ocraKey64, otp.OCRAInput{Question: "SIG1200000", Time: ocraTime}, "10235557"},
+			{ocraKey64, otp.OCRAInput{Question: "SIG1300000", Time: ocraTime}, "95213541"},
+			{ocraKey64, otp.OCRAInput{Question: "SIG1400000", Time: ocraTime}, "65360607"},
+		},
+	}
+	for name, vs := range tests {
+		suite, err := otp.ParseOCRASuite(name)
+		if err != nil {
+			t.Fatalf("ParseOCRASuite(%q): unexpected error: %v", name, err)
+		}
+		if got := suite.String(); got != name {
+			t.Errorf("Suite name: got %q, want %q", got, name)
+		}
+		for _, v := range vs {
+			got, err := suite.Generate([]byte(v.key), v.in)
+			if err != nil {
+				t.Errorf("%s: Generate(%+v): unexpected error: %v", name, v.in, err)
+			} else if got != v.want {
+				t.Errorf("%s: Generate(%+v): got %q, want %q", name, v.in, got, v.want)
+			}
+			if !suite.Verify([]byte(v.key), v.in, v.want) {
+				t.Errorf("%s: Verify(%+v, %q) failed", name, v.in, v.want)
+			}
+		}
+	}
+}
+
+func TestParseOCRASuite(t *testing.T) {
+	tests := []struct {
+		input  string
+		digits int
+		step   time.Duration
+	}{
+		{"OCRA-1:HOTP-SHA1-0:QH40", 0, 0},
+		{"OCRA-1:HOTP-SHA1-4:QA04-S064", 4, 0},
+		{"OCRA-1:HOTP-SHA256-10:C-QN64-PSHA256-S512-T30S", 10, 30 * time.Second},
+		{"OCRA-1:HOTP-SHA512-6:QH08-T48H", 6, 48 * time.Hour},
+	}
+	for _, tc := range tests {
+		s, err := otp.ParseOCRASuite(tc.input)
+		if err != nil {
+			t.Errorf("ParseOCRASuite(%q): unexpected error: %v", tc.input, err)
+			continue
+		}
+		if s.Digits() != tc.digits || s.TimeStep() != tc.step {
+			t.Errorf("ParseOCRASuite(%q): got digits %d, step %v; want %d, %v",
+				tc.input, s.Digits(), s.TimeStep(), tc.digits, tc.step)
+		}
+	}
+
+	bad := []string{
+		"",
+		"OCRA-1:HOTP-SHA1-6",
+		"OCRA-2:HOTP-SHA1-6:QN08",
+		"OCRA-1:TOTP-SHA1-6:QN08",
+		"OCRA-1:HOTP-MD5-6:QN08",
+		"OCRA-1:HOTP-SHA1-3:QN08",
+		"OCRA-1:HOTP-SHA1-11:QN08",
+		"OCRA-1:HOTP-SHA1-6:C",
+		"OCRA-1:HOTP-SHA1-6:QX08",
+		"OCRA-1:HOTP-SHA1-6:QN03",
+		"OCRA-1:HOTP-SHA1-6:QN65",
+		"OCRA-1:HOTP-SHA1-6:QN08-PMD5",
+		"OCRA-1:HOTP-SHA1-6:QN08-S64",
+		"OCRA-1:HOTP-SHA1-6:QN08-T60S",
+		"OCRA-1:HOTP-SHA1-6:QN08-T1X",
+		"OCRA-1:HOTP-SHA1-6:QN08-T1M-PSHA1",
+		"OCRA-1:HOTP-SHA1-6:QN08-C",
+	}
+	for _, s := range bad {
+		if got, err := otp.ParseOCRASuite(s); err == nil {
+			t.Errorf("ParseOCRASuite(%q): got %v, want error", s, got)
+		}
+	}
+}
+
+func TestOCRAInputErrors(t *testing.T) {
+	suite, err := otp.ParseOCRASuite("OCRA-1:HOTP-SHA1-6:QN08-PSHA1-S004")
+	if err != nil {
+		t.Fatalf("ParseOCRASuite: unexpected error: %v", err)
+	}
+	tests := []otp.OCRAInput{
+		{Question: "123"},
+		{Question: strings.Repeat("9", 400)},
+		{Question: "1234abcd"},
+		{Question: "12345678", PINHash: []byte("short")},
+		{Question: "12345678", Session: []byte("too long")},
+	}
+	for _, in := range tests {
+		if got, err := suite.Generate([]byte(ocraKey20), in); err == nil {
+			t.Errorf("Generate(%+v): got %q, want error", in, got)
+		}
+	}
+}
@@ -1,18 +1,21 @@
 // Copyright (C) 2019 Michael J. Fromberger. All Rights Reserved.
 
 // Package otp generates single use authenticator codes using the HOTP or TOTP
-// algorithms specified in RFC 4226 and RFC 6238 respectively.
+// algorithms specified in RFC 4226 and RFC 6238 respectively, and the OCRA
+// challenge-response algorithm specified in RFC 6287.
 //
-// See https://tools.ietf.org/html/rfc4226, https://tools.ietf.org/html/rfc6238
+// See https://tools.ietf.org/html/rfc4226, https://tools.ietf.org/html/rfc6238,
+// https://tools.ietf.org/html/rfc6287
 package otp
 
 import (
 	"crypto/hmac"
-	"crypto/sha1"
+	"crypto/rand"
 	"encoding/base32"
 	"encoding/binary"
 	"fmt"
 	"hash"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -46,7 +49,7 @@ func TimeWindow(n int) func() uint64 {
 	return func() uint64 { return uint64(time.Now().Unix()) / uint64(n) }
 }
 
-var timeWindow30 = TimeWindow(30) // default 30-second window
+const defaultPeriod = 30 * time.Second // default TOTP time step
 
 // Config holds the settings that control generation of authentication codes.
 // The only required field is Key. The other fields may be omitted, and will
@@ -57,14 +60,28 @@ type Config struct {
 	// The value must not be encoded in base32 or similar.
 	Key string
 
-	// Hash, if non-nil, is used to construct the hash for OTP generation.
-	// If nil, the default is sha1.New.
+	// Algorithm is the hash algorithm used for OTP generation.
+	// The default (zero value) is SHA1.
+	Algorithm Algorithm
+
+	// Hash, if non-nil, is used to construct the hash for OTP generation, and
+	// overrides Algorithm. Prefer Algorithm, since a Hash function cannot be
+	// inspected or serialized.
 	Hash func() hash.Hash
 
 	// TimeStep, if non-nil, returns the current time window to use for TOTP
-	// generation each time it is called. If nil, TimeWindow(30) is used.
+	// generation each time it is called. If nil, the current time window is
+	// computed from the wallclock time using Period and T0 (see StepAt).
 	TimeStep func() uint64
 
+	// Period is the duration of a TOTP time step. If zero or negative, the
+	// default is 30 seconds.
+	Period time.Duration
+
+	// T0 is the time at which TOTP time steps begin. If zero, the default is
+	// the Unix epoch (1970-01-01T00:00:00Z).
+	T0 time.Time
+
 	// Counter is the current HOTP counter value. It is incremented each time
 	// the Next method is called.
 	Counter uint64
@@ -115,6 +132,30 @@ func ParseKey(s string) ([]byte, error) {
 	return base32.StdEncoding.DecodeString(clean)
 }
 
+// GenerateKey generates a new random key for use with the hash algorithm alg,
+// reading random data from r. If r == nil, crypto/rand.Reader is used.
+// It reports an error if alg is not a known algorithm.
+//
+// The length of the key is the output size of the hash: 160 bits for SHA-1,
+// 256 bits for SHA-256, and 512 bits for SHA-512, satisfying the minimums
+// recommended by RFC 4226 and RFC 6238.
+//
+// It returns the raw key, and the key encoded as unpadded base32, the form
+// accepted by ParseKey and used in otpauth URLs.
+func GenerateKey(alg Algorithm, r io.Reader) ([]byte, string, error) {
+	if !alg.Valid() {
+		return nil, "", fmt.Errorf("generate key: unknown algorithm %d", int(alg))
+	}
+	if r == nil {
+		r = rand.Reader
+	}
+	key := make([]byte, alg.New().Size())
+	if _, err := io.ReadFull(r, key); err != nil {
+		return nil, "", fmt.Errorf("generate key: %w", err)
+	}
+	return key, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key), nil
+}
+
 // HOTP returns the HOTP code for the specified counter value.
 func (c Config) HOTP(counter uint64) string {
 	nd := c.digits()
@@ -134,11 +175,39 @@ func (c Config) TOTP() string {
 	return c.HOTP(c.timeStepWindow())
 }
 
+// TOTPAt returns the TOTP code for the time step containing t, as computed by
+// c.StepAt. The TimeStep field is not used.
+func (c Config) TOTPAt(t time.Time) string { return c.HOTP(c.StepAt(t)) }
+
+// StepAt returns the TOTP time step containing t, namely the number of whole
+// periods of length c.Period elapsed between c.T0 and t. Times before T0 are
+// in step 0. The TimeStep field is not used.
+func (c Config) StepAt(t time.Time) uint64 {
+	t0 := c.T0
+	if t0.IsZero() {
+		t0 = time.Unix(0, 0)
+	}
+	if !t.After(t0) {
+		return 0
+	}
+	p := c.period()
+	if p%time.Second == 0 {
+		// Work in seconds, so that spans too long for a time.Duration (about
+		// 292 years) are handled correctly.
+		secs := t.Unix() - t0.Unix()
+		if t.Nanosecond() < t0.Nanosecond() {
+			secs--
+		}
+		return uint64(secs) / uint64(p/time.Second)
+	}
+	return uint64(t.Sub(t0) / p)
+}
+
 func (c Config) newHash() func() hash.Hash {
 	if c.Hash != nil {
 		return c.Hash
 	}
-	return sha1.New
+	return c.Algorithm.New
 }
 
 func (c Config) digits() int {
@@ -148,18 +217,75 @@ func (c Config) digits() int {
 	return c.Digits
 }
 
+// A TimedCode is a TOTP code together with its time step and the interval of
+// time during which the step is current.
+type TimedCode struct {
+	Code  string    // the TOTP code
+	Step  uint64    // the time step of the code
+	Start time.Time // the start of the step (inclusive)
+	End   time.Time // the end of the step (exclusive)
+}
+
+// Remaining returns the duration from t until the code expires, or 0 if the
+// code has already expired at t.
+func (tc TimedCode) Remaining(t time.Time) time.Duration {
+	if !t.Before(tc.End) {
+		return 0
+	}
+	return tc.End.Sub(t)
+}
+
+// TimedTOTPAt returns the TOTP code for the time step containing t, as
+// computed by c.StepAt, along with the interval during which that step is
+// current. The TimeStep field is not used.
+func (c Config) TimedTOTPAt(t time.Time) TimedCode {
+	step := c.StepAt(t)
+	start := c.stepStart(step)
+	return TimedCode{
+		Code:  c.HOTP(step),
+		Step:  step,
+		Start: start,
+		End:   start.Add(c.period()),
+	}
+}
+
+// stepStart returns the time at which the specified TOTP time step begins.
+func (c Config) stepStart(step uint64) time.Time {
+	t0 := c.T0
+	if t0.IsZero() {
+		t0 = time.Unix(0, 0)
+	}
+	p := c.period()
+	if p%time.Second == 0 {
+		secs := int64(step) * int64(p/time.Second) // see StepAt
+		return time.Unix(t0.Unix()+secs, int64(t0.Nanosecond())).In(t0.Location())
+	}
+	return t0.Add(time.Duration(step) * p)
+}
+
+func (c Config) period() time.Duration {
+	if c.Period <= 0 {
+		return defaultPeriod
+	}
+	return c.Period
+}
+
 func (c Config) timeStepWindow() uint64 {
 	if c.TimeStep != nil {
 		return c.TimeStep()
 	}
-	return timeWindow30()
+	return c.StepAt(time.Now())
 }
 
-func (c Config) hmac(counter uint64) []byte {
+func (c Config) hmac(counter uint64) []byte { return c.hmacSuffix(counter, nil) }
+
+// hmacSuffix computes the HMAC of counter followed by suffix.
+func (c Config) hmacSuffix(counter uint64, suffix []byte) []byte {
 	var ctr [8]byte
 	binary.BigEndian.PutUint64(ctr[:], uint64(counter))
 	h := hmac.New(c.newHash(), []byte(c.Key))
 	h.Write(ctr[:])
+	h.Write(suffix)
 	return h.Sum(nil)
 }
 
@@ -3,12 +3,15 @@
 package otp_test
 
 import (
+	"bytes"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
 	"fmt"
 	"hash"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/creachadair/mds/mtest"
 	"github.com/creachadair/otp"
@@ -236,6 +239,167 @@ func TestRFC6238Vectors(t *testing.T) {
 	}
 }
 
+func TestConfig_TOTPAt(t *testing.T) {
+	for _, tc := range testVectors {
+		h := testHash[tc.alg]
+		cfg := otp.Config{Key: h.key, Hash: h.cons, Digits: len(tc.want)}
+		when := time.Unix(int64(tc.seconds), 0)
+		if got := cfg.TOTPAt(when); got != tc.want {
+			t.Errorf("%s TOTPAt(%d): got %q, want %q", tc.alg, tc.seconds, got, tc.want)
+		}
+	}
+
+	t.Run("PeriodT0", func(t *testing.T) {
+		t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		cfg := otp.Config{Key: "12345678901234567890", Period: time.Minute, T0: t0}
+
+		tests := []struct {
+			when time.Time
+			want uint64
+		}{
+			{t0.Add(-time.Hour), 0}, // before T0
+			{t0, 0},
+			{t0.Add(59*time.Second + 999*time.Millisecond), 0},
+			{t0.Add(time.Minute), 1},
+			{t0.Add(90 * time.Minute), 90},
+			{t0.Add(90*time.Minute - time.Nanosecond), 89},
+		}
+		for _, tc := range tests {
+			if got := cfg.StepAt(tc.when); got != tc.want {
+				t.Errorf("StepAt(%v): got %d, want %d", tc.when, got, tc.want)
+			}
+			if got, want := cfg.TOTPAt(tc.when), cfg.HOTP(tc.want); got != want {
+				t.Errorf("TOTPAt(%v): got %q, want %q", tc.when, got, want)
+			}
+		}
+
+		// Codes for adjacent periods are the neighbouring steps.
+		now := t0.Add(10 * time.Minute)
+		if got, want := cfg.TOTPAt(now.Add(-cfg.Period)), cfg.HOTP(9); got != want {
+			t.Errorf("Previous code: got %q, want %q", got, want)
+		}
+		if got, want := cfg.TOTPAt(now.Add(cfg.Period)), cfg.HOTP(11); got != want {
+			t.Errorf("Next code: got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("SubSecond", func(t *testing.T) {
+		cfg := otp.Config{Period: 250 * time.Millisecond}
+		if got, want := cfg.StepAt(time.Unix(10, 600_000_000)), uint64(42); got != want {
+			t.Errorf("StepAt: got %d, want %d", got, want)
+		}
+	})
+}
+
+func TestConfig_TimedTOTPAt(t *testing.T) {
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		cfg        otp.Config
+		when       time.Time
+		step       uint64
+		start, end time.Time
+	}{
+		{"Default", otp.Config{}, time.Unix(1111111109, 0),
+			1111111109 / 30, time.Unix(1111111080, 0), time.Unix(1111111110, 0)},
+		{"FarFuture", otp.Config{}, time.Unix(20000000000, 0),
+			20000000000 / 30, time.Unix(19999999980, 0), time.Unix(20000000010, 0)},
+		{"T0", otp.Config{T0: t0, Period: time.Minute}, t0.Add(150 * time.Second),
+			2, t0.Add(2 * time.Minute), t0.Add(3 * time.Minute)},
+		{"Before", otp.Config{T0: t0}, t0.Add(-time.Hour),
+			0, t0, t0.Add(30 * time.Second)},
+		{"SubSecond", otp.Config{T0: t0, Period: 400 * time.Millisecond}, t0.Add(time.Second),
+			2, t0.Add(800 * time.Millisecond), t0.Add(1200 * time.Millisecond)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cfg.Key = "12345678901234567890"
+			got := tc.cfg.TimedTOTPAt(tc.when)
+			if got.Step != tc.step || !got.Start.Equal(tc.start) || !got.End.Equal(tc.end) {
+				t.Errorf("TimedTOTPAt(%v): got step %d [%v, %v), want %d [%v, %v)",
+					tc.when, got.Step, got.Start, got.End, tc.step, tc.start, tc.end)
+			}
+			if want := tc.cfg.HOTP(tc.step); got.Code != want {
+				t.Errorf("TimedTOTPAt(%v): got code %q, want %q", tc.when, got.Code, want)
+			}
+		})
+	}
+
+	t.Run("Remaining", func(t *testing.T) {
+		tc := otp.Config{Key: "x"}.TimedTOTPAt(time.Unix(100, 0))
+		for _, r := range []struct {
+			when time.Time
+			want time.Duration
+		}{
+			{time.Unix(90, 0), 30 * time.Second},
+			{time.Unix(100, 0), 20 * time.Second},
+			{time.Unix(119, 500), 999999500},
+			{time.Unix(120, 0), 0},
+			{time.Unix(500, 0), 0},
+		} {
+			if got := tc.Remaining(r.when); got != r.want {
+				t.Errorf("Remaining(%v): got %v, want %v", r.when, got, r.want)
+			}
+		}
+	})
+}
+
+func TestGenerateKey(t *testing.T) {
+	for _, alg := range []otp.Algorithm{otp.SHA1, otp.SHA256, otp.SHA512, otp.SHA3_256, otp.SHA3_512} {
+		t.Run(alg.String(), func(t *testing.T) {
+			// Use a deterministic source of "random" data.
+			src := bytes.NewReader([]byte(strings.Repeat("0123456789abcdef", 8)))
+			key, enc, err := otp.GenerateKey(alg, src)
+			if err != nil {
+				t.Fatalf("GenerateKey: unexpected error: %v", err)
+			}
+			if want := alg.New().Size(); len(key) != want {
+				t.Errorf("Key length: got %d, want %d", len(key), want)
+			}
+			if strings.Contains(enc, "=") {
+				t.Errorf("Encoded key %q contains padding", enc)
+			}
+			dec, err := otp.ParseKey(enc)
+			if err != nil {
+				t.Fatalf("ParseKey(%q): unexpected error: %v", enc, err)
+			}
+			if !bytes.Equal(dec, key) {
+				t.Errorf("ParseKey(%q): got %x, want %x", enc, dec, key)
+			}
+		})
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		k1, _, err := otp.GenerateKey(0, nil)
+		if err != nil {
+			t.Fatalf("GenerateKey: unexpected error: %v", err)
+		}
+		k2, _, err := otp.GenerateKey(0, nil)
+		if err != nil {
+			t.Fatalf("GenerateKey: unexpected error: %v", err)
+		}
+		if len(k1) != sha1.Size {
+			t.Errorf("Key length: got %d, want %d", len(k1), sha1.Size)
+		}
+		if bytes.Equal(k1, k2) {
+			t.Errorf("Generated the same key twice: %x", k1)
+		}
+	})
+
+	t.Run("ShortRead", func(t *testing.T) {
+		src := strings.NewReader("too short")
+		if key, _, err := otp.GenerateKey(otp.SHA256, src); err == nil {
+			t.Errorf("GenerateKey: got %x, want error", key)
+		}
+	})
+
+	t.Run("BadAlgorithm", func(t *testing.T) {
+		if key, _, err := otp.GenerateKey(otp.Algorithm(99), nil); err == nil {
+			t.Errorf("GenerateKey: got %x, want error", key)
+		}
+	})
+}
+
 // digitsToLetters maps each decimal digit in s to the corresponding letter in
 // the range a..j. It will panic for any value outside this range.
 func digitsToLetters(s string) string {
@@ -0,0 +1,66 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otpauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// A SteamAccount is a Steam Guard account imported from a maFile.
+//
+// The identity secret and revocation code are kept apart from the URL, since
+// the string form of a URL includes all its parameters, and these values
+// should not be shared with an app that only needs to generate login codes.
+type SteamAccount struct {
+	URL *URL // the login code settings, as constructed by NewSteamURL
+
+	// IdentitySecret is the key used to sign trade confirmations, for use
+	// with otp.SteamConfirmationKey. It is nil if the file has none.
+	IdentitySecret []byte
+
+	// RevocationCode is the code used to remove the authenticator from the
+	// account, or "" if the file has none.
+	RevocationCode string
+}
+
+// maFile is the subset of the Steam Desktop Authenticator account format that
+// is retained on import.
+type maFile struct {
+	AccountName    string `json:"account_name"`
+	SharedSecret   string `json:"shared_secret"`
+	IdentitySecret string `json:"identity_secret"`
+	RevocationCode string `json:"revocation_code"`
+}
+
+// ParseMAFile parses data as an unencrypted Steam Desktop Authenticator
+// account file (.maFile), and returns the account it describes. Fields of the
+// file other than those in SteamAccount are discarded.
+func ParseMAFile(data []byte) (*SteamAccount, error) {
+	var mf maFile
+	if err := json.Unmarshal(data, &mf); err != nil {
+		return nil, fmt.Errorf("invalid maFile (encrypted files are not supported): %w", err)
+	}
+	if mf.AccountName == "" {
+		return nil, errors.New("missing account name")
+	}
+	if mf.SharedSecret == "" {
+		return nil, errors.New("missing shared secret")
+	}
+	key, err := decodeBase64(mf.SharedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shared secret: %w", err)
+	}
+	acct := &SteamAccount{
+		URL:            NewSteamURL(mf.AccountName, key),
+		RevocationCode: mf.RevocationCode,
+	}
+	if mf.IdentitySecret != "" {
+		acct.IdentitySecret, err = decodeBase64(mf.IdentitySecret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid identity secret: %w", err)
+		}
+	}
+	return acct, nil
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otpauth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/creachadair/otp/otpauth"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseMAFile(t *testing.T) {
+	const input = `{
+  "shared_secret": "AQIDBAUGBwgJCgsMDQ4PEBESExQ=",
+  "serial_number": "1234567890123456789",
+  "revocation_code": "R12345",
+  "uri": "otpauth://totp/Steam:alice?secret=AEBAGBAFAYDQQCIKBMGA2DQPCAIREEYU&issuer=Steam",
+  "server_time": 1700000000,
+  "account_name": "alice",
+  "token_gid": "2a6b1c3d4e5f",
+  "identity_secret": "ZWZnaGlqa2xtbm9wcXJzdHV2d3g=",
+  "secret_1": "c2VjcmV0",
+  "status": 1,
+  "device_id": "android:00000000-0000-0000-0000-000000000000",
+  "fully_enrolled": true,
+  "Session": {"SteamID": 76561197960287930}
+}`
+
+	acct, err := otpauth.ParseMAFile([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseMAFile: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(acct, &otpauth.SteamAccount{
+		URL: &otpauth.URL{
+			Type: "totp", Issuer: "Steam", Account: "alice",
+			RawSecret: "AEBAGBAFAYDQQCIKBMGA2DQPCAIREEYU",
+			Algorithm: "SHA1", Digits: 5, Period: 30, Encoder: "steam",
+		},
+		IdentitySecret: []byte("efghijklmnopqrstuvwx"),
+		RevocationCode: "R12345",
+	}); diff != "" {
+		t.Errorf("ParseMAFile (-got, +want):\n%s", diff)
+	}
+
+	// The account secrets must not appear in the URL string.
+	const want = "otpauth://totp/Steam:alice?digits=5&encoder=steam&issuer=Steam&secret=AEBAGBAFAYDQQCIKBMGA2DQPCAIREEYU"
+	if got := acct.URL.String(); got != want {
+		t.Errorf("String:\n got %q\nwant %q", got, want)
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, bad := range []string{
+			``,
+			`not json`,
+			`{"shared_secret": "AQIDBAUGBwgJCgsMDQ4PEBESExQ="}`,
+			`{"account_name": "alice"}`,
+			`{"account_name": "alice", "shared_secret": "*bogus*"}`,
+			`{"account_name": "alice", "shared_secret": "AQID", "identity_secret": "!!"}`,
+		} {
+			if acct, err := otpauth.ParseMAFile([]byte(bad)); err == nil {
+				t.Errorf("ParseMAFile %q: got %+v, want error", bad, acct)
+			}
+		}
+	})
+}
+
+func TestParseMAFile_Codes(t *testing.T) {
+	// The shared secret is "superdupersecret", for which the ValvePython/steam
+	// library publishes reference codes.
+	const input = `{"account_name": "gamer", "shared_secret": "c3VwZXJkdXBlcnNlY3JldA=="}`
+
+	acct, err := otpauth.ParseMAFile([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseMAFile: unexpected error: %v", err)
+	}
+	cfg, err := acct.URL.Config()
+	if err != nil {
+		t.Fatalf("Config: unexpected error: %v", err)
+	}
+	for _, tc := range []struct {
+		unix int64
+		want string
+	}{
+		{3000030, "YRGQJ"},
+		{3000029, "94R9D"},
+	} {
+		if got := cfg.TOTPAt(time.Unix(tc.unix, 0)); got != tc.want {
+			t.Errorf("TOTPAt(%d): got %q, want %q", tc.unix, got, tc.want)
+		}
+	}
+}
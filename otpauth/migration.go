@@ -2,6 +2,7 @@ package otpauth
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/base64"
 	"encoding/binary"
 	"errors"
@@ -23,6 +24,41 @@ import (
 // settings; on success this function returns all the otpauth URLs encoded by
 // the content.  It will always return at least one URL, or report an error.
 func ParseMigrationURL(s string) ([]*URL, error) {
+	m, err := ParseMigration(s)
+	if err != nil {
+		return nil, err
+	}
+	return m.URLs, nil
+}
+
+// A Migration is the parsed content of a single migration URL.
+type Migration struct {
+	URLs []*URL // the accounts encoded by the URL
+
+	// When a large export is split across multiple migration URLs, these
+	// fields describe how this URL fits into the complete batch.
+	// See MigrationCollector.
+
+	Version    int   // the format version
+	BatchSize  int   // the number of URLs in the batch
+	BatchIndex int   // the index of this URL in the batch, from 0
+	BatchID    int32 // the identifier shared by all URLs of the batch
+
+	// Warnings describe problems found while decoding in lenient mode.
+	// See [ParseOptions].
+	Warnings []string
+}
+
+// ParseMigration parses an otpauth-migration URL as ParseMigrationURL does,
+// but also reports the batch metadata from the URL.
+func ParseMigration(s string) (*Migration, error) { return (*ParseOptions)(nil).ParseMigration(s) }
+
+// ParseMigration parses an otpauth-migration URL as ParseMigrationURL does,
+// subject to the settings of o, and reports the batch metadata from the URL.
+//
+// The base64 content may use either the standard or URL-safe alphabet, with
+// or without padding, as produced by various third-party exporters.
+func (o *ParseOptions) ParseMigration(s string) (*Migration, error) {
 	rest, ok := strings.CutPrefix(s, "otpauth-migration://")
 	if !ok {
 		return nil, errors.New("missing otpauth-migration schema prefix")
@@ -35,13 +71,33 @@ func ParseMigrationURL(s string) ([]*URL, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid data: %w", err)
 	}
-	bits, err := base64.StdEncoding.DecodeString(dec)
+	bits, err := decodeBase64(dec)
 	if err != nil {
 		return nil, fmt.Errorf("invalid base64: %w", err)
 	}
-	return parseMigrations(bits)
+	return parseMigrations(bits, o.lenient())
 }
 
+// decodeBase64 decodes s as base64 in either the standard or URL-safe
+// alphabet, with or without padding.
+func decodeBase64(s string) ([]byte, error) {
+	norm := strings.NewReplacer(
+		" ", "+", // an unescaped "+" in a query is decoded as a space
+		"-", "+",
+		"_", "/",
+	).Replace(strings.TrimRight(s, "="))
+	return base64.RawStdEncoding.DecodeString(norm)
+}
+
+// Field IDs of the Content message.
+const (
+	paramsField = 1 + iota
+	versionField
+	batchSizeField
+	batchIndexField
+	batchIDField
+)
+
 /*
 The content of a migration URL is a wire-format protocol buffer message.
 
@@ -52,10 +108,14 @@ fairly simple:
    message Content {
       repeated Params params = 1;
 
-      // ... other fields not of interest.
       // If you're exporting more data than can fit in one QR code, the app may
-      // split up the export into multiple codes. There are some fields here to
-      // keep track of that, but they aren't relevant here.
+      // split up the export into multiple codes. These fields keep track of
+      // that: batch_size is the number of codes, batch_index is the index of
+      // this code (from 0), and batch_id is shared by all codes of the export.
+      int32 version     = 2;
+      int32 batch_size  = 3;
+      int32 batch_index = 4;
+      int32 batch_id    = 5;
    }
 
    message Params {
@@ -68,35 +128,54 @@ fairly simple:
       uint64 counter   = 7;
    }
 
-So here we just unpack the wire format directly.
+So here we just unpack (or pack) the wire format directly.
 */
 
 // parseMigrations parses data as a wire-format protobuf message in the Content
 // format described above, and returns a single URL for each instance of the
-// Params found therein. Other fields of the message are ignored.
-func parseMigrations(data []byte) ([]*URL, error) {
-	const paramsField = 1
-
-	var out []*URL
+// Params found therein, along with the batch metadata. See parseParams for the
+// meaning of lenient.
+func parseMigrations(data []byte, lenient bool) (*Migration, error) {
+	var out Migration
 	s := wirepb.NewScanner(bytes.NewReader(data))
 	for s.Next() {
-		if s.ID() == paramsField {
-			u, err := parseParams(s.Data())
+		switch s.ID() {
+		case paramsField:
+			u, warn, err := parseParams(s.Data(), lenient)
 			if err != nil {
 				return nil, err
 			}
-			out = append(out, u)
+			out.URLs = append(out.URLs, u)
+			out.Warnings = append(out.Warnings, warn...)
+		case versionField:
+			out.Version = int(parseInt32(s.Data()))
+		case batchSizeField:
+			out.BatchSize = int(parseInt32(s.Data()))
+		case batchIndexField:
+			out.BatchIndex = int(parseInt32(s.Data()))
+		case batchIDField:
+			out.BatchID = parseInt32(s.Data())
 		}
 	}
 	if s.Err() != nil {
 		return nil, s.Err()
-	} else if len(out) == 0 {
+	} else if len(out.URLs) == 0 {
 		return nil, errors.New("no URLs found")
 	}
-	return out, nil
+	return &out, nil
+}
+
+// parseInt32 decodes data as a varint-encoded int32 field. Negative values
+// are sign-extended to 64 bits on the wire, and are truncated here.
+func parseInt32(data []byte) int32 {
+	v, _ := binary.Uvarint(data)
+	return int32(v)
 }
 
-func parseParams(data []byte) (*URL, error) {
+// parseParams parses data as a wire-format Params message. If lenient is
+// true, unspecified enum values are replaced by defaults, and unknown enum
+// values are reported as warnings rather than errors.
+func parseParams(data []byte, lenient bool) (*URL, []string, error) {
 	const (
 		secretField = 1 + iota
 		accountField
@@ -108,6 +187,14 @@ func parseParams(data []byte) (*URL, error) {
 	)
 
 	var out = URL{Algorithm: defaultAlgorithm, Digits: defaultDigits, Period: defaultPeriod}
+	var warn []error
+	unknown := func(err error) error {
+		if lenient {
+			warn = append(warn, err)
+			return nil
+		}
+		return err
+	}
 	s := wirepb.NewScanner(bytes.NewReader(data))
 	for s.Next() {
 		switch s.ID() {
@@ -119,6 +206,10 @@ func parseParams(data []byte) (*URL, error) {
 			out.Issuer = string(s.Data())
 		case algorithmField:
 			switch v, _ := binary.Uvarint(s.Data()); v {
+			case 0:
+				if !lenient {
+					return nil, nil, errors.New("unspecified algorithm")
+				}
 			case 1:
 				out.Algorithm = "SHA1"
 			case 2:
@@ -128,36 +219,344 @@ func parseParams(data []byte) (*URL, error) {
 			case 4:
 				out.Algorithm = "MD5"
 			default:
-				return nil, fmt.Errorf("unknown algorithm code %d", v)
+				// Keep the default algorithm.
+				if err := unknown(fmt.Errorf("unknown algorithm code %d", v)); err != nil {
+					return nil, nil, err
+				}
 			}
 		case digitsField:
 			switch v, _ := binary.Uvarint(s.Data()); v {
+			case 0:
+				if !lenient {
+					return nil, nil, errors.New("unspecified digits")
+				}
 			case 1:
 				out.Digits = 6
 			case 2:
 				out.Digits = 8
 			default:
-				return nil, fmt.Errorf("unknown digits code %d", v)
+				// Some exporters write the digit count itself rather than the
+				// enumerator. Preserve the value, since it is likely correct.
+				if err := unknown(fmt.Errorf("unknown digits code %d", v)); err != nil {
+					return nil, nil, err
+				}
+				out.Digits = int(min(v, 100))
 			}
 		case typeField:
 			switch v, _ := binary.Uvarint(s.Data()); v {
+			case 0:
+				if !lenient {
+					return nil, nil, errors.New("unspecified type")
+				}
 			case 1:
 				out.Type = "hotp"
 			case 2:
 				out.Type = "totp"
 			default:
-				return nil, fmt.Errorf("unknown type code %d", v)
+				// Leave the type to be defaulted below.
+				if err := unknown(fmt.Errorf("unknown type code %d", v)); err != nil {
+					return nil, nil, err
+				}
 			}
 		case counterField:
 			v, n := binary.Uvarint(s.Data())
 			if n <= 0 {
-				return nil, errors.New("invalid counter value")
+				return nil, nil, errors.New("invalid counter value")
 			}
 			out.Counter = v
 		}
 	}
 	if s.Err() != nil {
-		return nil, s.Err()
+		return nil, nil, s.Err()
 	}
-	return &out, nil
+	if lenient && out.Type == "" {
+		out.Type = "totp"
+	}
+
+	// Label warnings with the account, which may not have been known when
+	// they were generated.
+	var msgs []string
+	for _, w := range warn {
+		msgs = append(msgs, fmt.Sprintf("account %q: %v", out.Account, w))
+	}
+	return &out, msgs, nil
+}
+
+// migrationVersion is the Content version written by FormatMigrationURLs.
+const migrationVersion = 2
+
+// migrationPrefix is the common prefix of all migration URLs.
+const migrationPrefix = "otpauth-migration://offline?data="
+
+// ExportOptions control the encoding of migration URLs by FormatMigrationURLs.
+// A nil *ExportOptions is ready for use and provides default values.
+type ExportOptions struct {
+	// MaxLength is the maximum length in bytes of each migration URL.
+	// Accounts are split across as many URLs as needed to respect this limit.
+	// If zero or negative, the default is 1024, which fits comfortably in a
+	// QR code that a phone camera can read.
+	MaxLength int
+
+	// BatchID is the batch identifier shared by all the URLs of the export.
+	// If zero, a random identifier is chosen.
+	BatchID int32
+}
+
+func (o *ExportOptions) maxLength() int {
+	if o == nil || o.MaxLength <= 0 {
+		return 1024
+	}
+	return o.MaxLength
+}
+
+func (o *ExportOptions) batchID() int32 {
+	if o != nil && o.BatchID != 0 {
+		return o.BatchID
+	}
+	var buf [4]byte
+	rand.Read(buf[:])
+	return int32(binary.BigEndian.Uint32(buf[:]) >> 1) // non-negative
+}
+
+// FormatMigrationURLs encodes urls as one or more otpauth-migration URLs in
+// the format read by ParseMigrationURL and by the Google Authenticator
+// "import" feature. The accounts are split into as many URLs as necessary to
+// keep each URL within the length limit set by opts, and the URLs are marked
+// with the batch information needed to reassemble them.
+//
+// It reports an error if any of the urls cannot be represented in the
+// migration format, which supports only TOTP and HOTP with 6 or 8 digits, a
+// 30-second period, and the SHA1, SHA256, SHA512, and MD5 algorithms.
+func FormatMigrationURLs(urls []*URL, opts *ExportOptions) ([]string, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no URLs to export")
+	}
+	params := make([][]byte, len(urls))
+	for i, u := range urls {
+		p, err := formatParams(u)
+		if err != nil {
+			return nil, fmt.Errorf("account %q: %w", u.Account, err)
+		}
+		params[i] = p
+	}
+	limit, id := opts.maxLength(), opts.batchID()
+
+	// The encoded length of each batch depends on the total number of batches,
+	// which is not known until the accounts are packed. Pack assuming a batch
+	// count, and repeat with the actual count until they agree.
+	nb := 1
+	for range 8 {
+		out, err := packMigrations(params, nb, id, limit)
+		if err != nil {
+			return nil, err
+		} else if len(out) == nb {
+			return out, nil
+		}
+		nb = len(out)
+	}
+	return nil, errors.New("unable to partition accounts")
+}
+
+// packMigrations greedily packs params into migration URLs of at most limit
+// bytes, each labelled with a batch size of nb.
+func packMigrations(params [][]byte, nb int, id int32, limit int) ([]string, error) {
+	var out []string
+	var cur, next []byte
+	var n int // number of params in cur
+	for _, p := range params {
+		next = appendBytesField(next[:0], paramsField, p)
+		if n > 0 {
+			trial := formatMigration(append(cur, next...), nb, len(out), id)
+			if len(trial) <= limit {
+				cur = append(cur, next...)
+				n++
+				continue
+			}
+			out = append(out, formatMigration(cur, nb, len(out), id))
+			cur, n = cur[:0], 0
+		}
+		if s := formatMigration(next, nb, len(out), id); len(s) > limit {
+			return nil, fmt.Errorf("account does not fit in %d bytes", limit)
+		}
+		cur = append(cur, next...)
+		n++
+	}
+	return append(out, formatMigration(cur, nb, len(out), id)), nil
+}
+
+// formatMigration renders a migration URL for the given encoded params.
+func formatMigration(params []byte, size, index int, id int32) string {
+	// Google Authenticator writes the batch fields even when they are zero.
+	msg := append([]byte(nil), params...)
+	msg = appendVarint(msg, versionField, migrationVersion)
+	msg = appendVarint(msg, batchSizeField, uint64(size))
+	msg = appendVarint(msg, batchIndexField, uint64(index))
+	msg = appendVarint(msg, batchIDField, uint64(id))
+	return migrationPrefix + url.QueryEscape(base64.StdEncoding.EncodeToString(msg))
+}
+
+// formatParams encodes u as a wire-format Params message.
+func formatParams(u *URL) ([]byte, error) {
+	var alg, digits, typ uint64
+	switch strings.ToUpper(u.Algorithm) {
+	case "", "SHA1":
+		alg = 1
+	case "SHA256":
+		alg = 2
+	case "SHA512":
+		alg = 3
+	case "MD5":
+		alg = 4
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", u.Algorithm)
+	}
+	switch u.Digits {
+	case 0, 6:
+		digits = 1
+	case 8:
+		digits = 2
+	default:
+		return nil, fmt.Errorf("unsupported digits %d", u.Digits)
+	}
+	switch strings.ToLower(u.Type) {
+	case "hotp":
+		typ = 1
+	case "totp":
+		typ = 2
+	default:
+		return nil, fmt.Errorf("unsupported type %q", u.Type)
+	}
+	if u.Period != 0 && u.Period != defaultPeriod {
+		return nil, fmt.Errorf("unsupported period %d", u.Period)
+	}
+	secret, err := u.Secret()
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret: %w", err)
+	}
+
+	const (
+		secretField = 1 + iota
+		accountField
+		issuerField
+		algorithmField
+		digitsField
+		typeField
+		counterField
+	)
+	var buf []byte
+	buf = appendBytesField(buf, secretField, secret)
+	buf = appendBytesField(buf, accountField, []byte(u.Account))
+	if u.Issuer != "" {
+		buf = appendBytesField(buf, issuerField, []byte(u.Issuer))
+	}
+	buf = appendVarintField(buf, algorithmField, alg)
+	buf = appendVarintField(buf, digitsField, digits)
+	buf = appendVarintField(buf, typeField, typ)
+	if typ == 1 {
+		buf = appendVarintField(buf, counterField, u.Counter)
+	}
+	return buf, nil
+}
+
+// appendVarintField appends a varint field to buf, omitting zero values as
+// the proto3 encoding does.
+func appendVarintField(buf []byte, id int, v uint64) []byte {
+	if v == 0 {
+		return buf
+	}
+	return appendVarint(buf, id, v)
+}
+
+// appendVarint appends a varint field to buf, even if v == 0.
+func appendVarint(buf []byte, id int, v uint64) []byte {
+	buf = binary.AppendUvarint(buf, uint64(id)<<3|0) // wire type 0: varint
+	return binary.AppendUvarint(buf, v)
+}
+
+// appendBytesField appends a length-delimited field to buf.
+func appendBytesField(buf []byte, id int, data []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(id)<<3|2) // wire type 2: length-delimited
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+// maxBatchSize is the largest batch size accepted by a MigrationCollector.
+// Google Authenticator splits an export into at most a few dozen parts.
+const maxBatchSize = 256
+
+// A MigrationCollector reassembles a batch of migration URLs, such as those
+// exported by Google Authenticator as a series of QR codes. The URLs of the
+// batch may be added in any order. A zero MigrationCollector is ready for use.
+type MigrationCollector struct {
+	id    int32
+	parts [][]*URL // by batch index; nil for missing parts
+}
+
+// Add parses s as a migration URL and adds it to the batch.
+// See [MigrationCollector.AddMigration].
+func (c *MigrationCollector) Add(s string) error {
+	m, err := ParseMigration(s)
+	if err != nil {
+		return err
+	}
+	return c.AddMigration(m)
+}
+
+// AddMigration adds m to the batch. It reports an error if m does not belong
+// to the same batch as the migrations previously added, if a migration
+// with the same batch index was already added, or if the batch size is
+// negative or implausibly large.
+func (c *MigrationCollector) AddMigration(m *Migration) error {
+	if m.BatchSize < 0 || m.BatchSize > maxBatchSize {
+		return fmt.Errorf("invalid batch size %d", m.BatchSize)
+	}
+	size := max(m.BatchSize, 1) // older exports may omit batch information
+	if m.BatchIndex < 0 || m.BatchIndex >= size {
+		return fmt.Errorf("batch index %d out of range for batch size %d", m.BatchIndex, size)
+	}
+	if c.parts == nil {
+		c.id = m.BatchID
+		c.parts = make([][]*URL, size)
+	} else if m.BatchID != c.id {
+		return fmt.Errorf("batch ID %d does not match %d", m.BatchID, c.id)
+	} else if size != len(c.parts) {
+		return fmt.Errorf("batch size %d does not match %d", size, len(c.parts))
+	}
+	if c.parts[m.BatchIndex] != nil {
+		return fmt.Errorf("duplicate batch index %d", m.BatchIndex)
+	}
+	c.parts[m.BatchIndex] = m.URLs
+	return nil
+}
+
+// Missing returns the batch indices that have not yet been added, in
+// increasing order. If nothing has been added yet, it returns nil.
+func (c *MigrationCollector) Missing() []int {
+	var out []int
+	for i, p := range c.parts {
+		if p == nil {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// Complete reports whether every part of the batch has been added.
+func (c *MigrationCollector) Complete() bool {
+	return len(c.parts) != 0 && len(c.Missing()) == 0
+}
+
+// URLs returns the combined URLs of all parts of the batch, in batch order.
+// It reports an error if the batch is not complete.
+func (c *MigrationCollector) URLs() ([]*URL, error) {
+	if len(c.parts) == 0 {
+		return nil, errors.New("no migrations added")
+	} else if miss := c.Missing(); len(miss) != 0 {
+		return nil, fmt.Errorf("batch incomplete: missing %d of %d parts %v", len(miss), len(c.parts), miss)
+	}
+	var out []*URL
+	for _, p := range c.parts {
+		out = append(out, p...)
+	}
+	return out, nil
 }
@@ -0,0 +1,345 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otpauth_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/creachadair/otp/otpauth"
+	"github.com/google/go-cmp/cmp"
+)
+
+// googleExport is a migration URL exported by Google Authenticator.
+// See TestParseMigrationURL for how it was generated.
+const googleExport = `otpauth-migration://offline?data=CiEKDy0zlZA0zlZDICFZBoCFZBIGdGVzdCAxIAEoATABOAMKGgoKA96yPQREnkAI%2BBIGdGVzdCAyIAEoATACEAIYASAA`
+
+func migrationData(t *testing.T, s string) []byte {
+	t.Helper()
+	enc, err := url.QueryUnescape(strings.TrimPrefix(s, "otpauth-migration://offline?data="))
+	if err != nil {
+		t.Fatalf("Unescape %q: %v", s, err)
+	}
+	data, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		t.Fatalf("Decode %q: %v", s, err)
+	}
+	return data
+}
+
+func TestFormatMigrationURLs(t *testing.T) {
+	t.Run("Google", func(t *testing.T) {
+		urls, err := otpauth.ParseMigrationURL(googleExport)
+		if err != nil {
+			t.Fatalf("ParseMigrationURL: unexpected error: %v", err)
+		}
+		got, err := otpauth.FormatMigrationURLs(urls, &otpauth.ExportOptions{BatchID: 12345})
+		if err != nil {
+			t.Fatalf("FormatMigrationURLs: unexpected error: %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("FormatMigrationURLs: got %d URLs, want 1", len(got))
+		}
+
+		// The result should match what Google generated, apart from the batch
+		// ID, which Google omitted.
+		want := migrationData(t, googleExport)
+		if data := migrationData(t, got[0]); !bytes.HasPrefix(data, want) {
+			t.Errorf("Encoding:\n got %x\nwant %x + batch ID", data, want)
+		}
+
+		// Check that it round-trips.
+		back, err := otpauth.ParseMigrationURL(got[0])
+		if err != nil {
+			t.Fatalf("ParseMigrationURL: unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(back, urls); diff != "" {
+			t.Errorf("Round trip (-got, +want):\n%s", diff)
+		}
+	})
+
+	t.Run("Batches", func(t *testing.T) {
+		var urls []*otpauth.URL
+		for i := range 40 {
+			u := &otpauth.URL{
+				Type:      "totp",
+				Issuer:    "Example Corp",
+				Account:   fmt.Sprintf("user%02d@example.com", i),
+				Algorithm: "SHA256",
+				Digits:    8,
+				Period:    30,
+			}
+			u.SetSecret([]byte(fmt.Sprintf("secret-key-number-%02d", i)))
+			urls = append(urls, u)
+		}
+		const maxLength = 500
+		got, err := otpauth.FormatMigrationURLs(urls, &otpauth.ExportOptions{MaxLength: maxLength})
+		if err != nil {
+			t.Fatalf("FormatMigrationURLs: unexpected error: %v", err)
+		}
+		if len(got) < 2 {
+			t.Fatalf("FormatMigrationURLs: got %d URLs, want several", len(got))
+		}
+		var all []*otpauth.URL
+		for i, s := range got {
+			if len(s) > maxLength {
+				t.Errorf("URL %d has length %d > %d", i, len(s), maxLength)
+			}
+			us, err := otpauth.ParseMigrationURL(s)
+			if err != nil {
+				t.Fatalf("ParseMigrationURL %d: unexpected error: %v", i, err)
+			}
+			all = append(all, us...)
+		}
+		if diff := cmp.Diff(all, urls); diff != "" {
+			t.Errorf("Round trip (-got, +want):\n%s", diff)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []*otpauth.URL{
+			{Type: "motp", Account: "x"},
+			{Type: "totp", Account: "x", Algorithm: "SHA3-256"},
+			{Type: "totp", Account: "x", Digits: 7},
+			{Type: "totp", Account: "x", Period: 60},
+			{Type: "totp", Account: "x", RawSecret: "not base32!"},
+			{Type: "totp", Account: strings.Repeat("x", 2000)},
+		}
+		for _, u := range tests {
+			if got, err := otpauth.FormatMigrationURLs([]*otpauth.URL{u}, nil); err == nil {
+				t.Errorf("FormatMigrationURLs(%v): got %q, want error", u, got)
+			}
+		}
+		if got, err := otpauth.FormatMigrationURLs(nil, nil); err == nil {
+			t.Errorf("FormatMigrationURLs(nil): got %q, want error", got)
+		}
+	})
+}
+
+func TestParseMigration(t *testing.T) {
+	m, err := otpauth.ParseMigration(googleExport)
+	if err != nil {
+		t.Fatalf("ParseMigration: unexpected error: %v", err)
+	}
+	if m.Version != 2 || m.BatchSize != 1 || m.BatchIndex != 0 || m.BatchID != 0 {
+		t.Errorf("ParseMigration: got version %d, batch %d/%d ID %d; want 2, 0/1 ID 0",
+			m.Version, m.BatchIndex, m.BatchSize, m.BatchID)
+	}
+	if len(m.URLs) != 2 {
+		t.Errorf("ParseMigration: got %d URLs, want 2", len(m.URLs))
+	}
+}
+
+func TestMigrationCollector(t *testing.T) {
+	var urls []*otpauth.URL
+	for i := range 25 {
+		u := &otpauth.URL{Type: "totp", Account: fmt.Sprintf("account-%02d", i), Algorithm: "SHA1", Digits: 6, Period: 30}
+		u.SetSecret([]byte(fmt.Sprintf("the secret key for %02d", i)))
+		urls = append(urls, u)
+	}
+	export := func(id int32) []string {
+		t.Helper()
+		out, err := otpauth.FormatMigrationURLs(urls, &otpauth.ExportOptions{MaxLength: 400, BatchID: id})
+		if err != nil {
+			t.Fatalf("FormatMigrationURLs: unexpected error: %v", err)
+		}
+		if len(out) < 3 {
+			t.Fatalf("FormatMigrationURLs: got %d URLs, want at least 3", len(out))
+		}
+		return out
+	}
+	parts := export(101)
+
+	var c otpauth.MigrationCollector
+	if c.Complete() || c.Missing() != nil {
+		t.Errorf("Empty collector: complete=%v, missing=%v", c.Complete(), c.Missing())
+	}
+	if _, err := c.URLs(); err == nil {
+		t.Error("URLs on empty collector: got nil, want error")
+	}
+
+	// Add the parts in reverse order, checking progress as we go.
+	for i := len(parts) - 1; i >= 0; i-- {
+		if err := c.Add(parts[i]); err != nil {
+			t.Fatalf("Add part %d: unexpected error: %v", i, err)
+		}
+		var want []int
+		for j := range i {
+			want = append(want, j)
+		}
+		if diff := cmp.Diff(c.Missing(), want); diff != "" {
+			t.Errorf("Missing after part %d (-got, +want):\n%s", i, diff)
+		}
+		if i > 0 {
+			if c.Complete() {
+				t.Errorf("Complete after part %d: got true, want false", i)
+			}
+			if got, err := c.URLs(); err == nil {
+				t.Errorf("URLs after part %d: got %d URLs, want error", i, len(got))
+			}
+		}
+	}
+	if !c.Complete() {
+		t.Error("Complete: got false, want true")
+	}
+	got, err := c.URLs()
+	if err != nil {
+		t.Fatalf("URLs: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(got, urls); diff != "" {
+		t.Errorf("URLs (-got, +want):\n%s", diff)
+	}
+
+	// Duplicates and parts from other batches are rejected.
+	if err := c.Add(parts[1]); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("Add duplicate: got %v, want duplicate error", err)
+	}
+	if err := c.Add(export(202)[0]); err == nil || !strings.Contains(err.Error(), "batch ID") {
+		t.Errorf("Add mismatched: got %v, want batch ID error", err)
+	}
+	if err := c.Add("otpauth-migration://offline?data=bogus"); err == nil {
+		t.Error("Add invalid: got nil, want error")
+	}
+
+	t.Run("BadSize", func(t *testing.T) {
+		// A batch size taken from the input must not be trusted to allocate.
+		batch := func(size uint64) string {
+			var p []byte
+			p = append(p, bytesField(1, []byte("hello"))...)
+			p = append(p, bytesField(2, []byte("alice"))...)
+			p = append(p, varintField(4, 1)...)
+			p = append(p, varintField(5, 1)...)
+			p = append(p, varintField(6, 2)...)
+			data := append(bytesField(1, p), varintField(3, size)...)
+			return "otpauth-migration://offline?data=" +
+				url.QueryEscape(base64.StdEncoding.EncodeToString(data))
+		}
+		for _, size := range []uint64{1 << 30, 257, math.MaxUint64} { // MaxUint64 is -1
+			var c otpauth.MigrationCollector
+			if err := c.Add(batch(size)); err == nil || !strings.Contains(err.Error(), "batch size") {
+				t.Errorf("Add with batch size %d: got %v, want batch size error", int32(size), err)
+			}
+		}
+		var c otpauth.MigrationCollector
+		if err := c.Add(batch(256)); err != nil {
+			t.Errorf("Add with batch size 256: unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Single", func(t *testing.T) {
+		var c otpauth.MigrationCollector
+		if err := c.Add(googleExport); err != nil {
+			t.Fatalf("Add: unexpected error: %v", err)
+		}
+		if !c.Complete() {
+			t.Error("Complete: got false, want true")
+		}
+	})
+}
+
+// varintField and bytesField encode protobuf wire-format fields for tests.
+func varintField(id int, v uint64) []byte {
+	return binary.AppendUvarint(binary.AppendUvarint(nil, uint64(id)<<3), v)
+}
+
+func bytesField(id int, data []byte) []byte {
+	buf := binary.AppendUvarint(nil, uint64(id)<<3|2)
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+func TestParseMigrationLenient(t *testing.T) {
+	// Construct a payload with a single Params message with the given enum
+	// values for algorithm, digits, and type.
+	payload := func(alg, digits, typ uint64) string {
+		var p []byte
+		p = append(p, bytesField(1, []byte("hello"))...)
+		p = append(p, bytesField(2, []byte("alice"))...)
+		p = append(p, varintField(4, alg)...)
+		p = append(p, varintField(5, digits)...)
+		p = append(p, varintField(6, typ)...)
+		return "otpauth-migration://offline?data=" +
+			url.QueryEscape(base64.StdEncoding.EncodeToString(bytesField(1, p)))
+	}
+	lenient := &otpauth.ParseOptions{Lenient: true}
+
+	tests := []struct {
+		name       string
+		input      string
+		want       *otpauth.URL
+		nwarn      int
+		strictFail bool
+	}{
+		{"Normal", payload(2, 2, 1),
+			&otpauth.URL{Type: "hotp", Algorithm: "SHA256", Digits: 8}, 0, false},
+		{"Unspecified", payload(0, 0, 0),
+			&otpauth.URL{Type: "totp", Algorithm: "SHA1", Digits: 6}, 0, true},
+		{"UnknownAlgorithm", payload(9, 1, 2),
+			&otpauth.URL{Type: "totp", Algorithm: "SHA1", Digits: 6}, 1, true},
+		{"LiteralDigits", payload(1, 7, 2),
+			&otpauth.URL{Type: "totp", Algorithm: "SHA1", Digits: 7}, 1, true},
+		{"UnknownType", payload(1, 1, 5),
+			&otpauth.URL{Type: "totp", Algorithm: "SHA1", Digits: 6}, 1, true},
+		{"AllUnknown", payload(5, 6, 7),
+			&otpauth.URL{Type: "totp", Algorithm: "SHA1", Digits: 6}, 3, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := otpauth.ParseMigration(tc.input)
+			if tc.strictFail && err == nil {
+				t.Error("ParseMigration (strict): got nil, want error")
+			} else if !tc.strictFail && err != nil {
+				t.Errorf("ParseMigration (strict): unexpected error: %v", err)
+			}
+
+			m, err := lenient.ParseMigration(tc.input)
+			if err != nil {
+				t.Fatalf("ParseMigration (lenient): unexpected error: %v", err)
+			}
+			tc.want.Account = "alice"
+			tc.want.Period = 30
+			tc.want.SetSecret([]byte("hello"))
+			if diff := cmp.Diff(m.URLs, []*otpauth.URL{tc.want}); diff != "" {
+				t.Errorf("ParseMigration (lenient) (-got, +want):\n%s", diff)
+			}
+			if len(m.Warnings) != tc.nwarn {
+				t.Errorf("Warnings: got %q, want %d", m.Warnings, tc.nwarn)
+			}
+			for _, w := range m.Warnings {
+				if !strings.Contains(w, `"alice"`) {
+					t.Errorf("Warning %q does not mention the account", w)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMigrationBase64(t *testing.T) {
+	want, err := otpauth.ParseMigrationURL(googleExport)
+	if err != nil {
+		t.Fatalf("ParseMigrationURL: unexpected error: %v", err)
+	}
+	data := migrationData(t, googleExport)
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		s := enc.EncodeToString(data)
+		for _, input := range []string{
+			"otpauth-migration://offline?data=" + url.QueryEscape(s),
+			"otpauth-migration://offline?data=" + s, // not escaped
+		} {
+			got, err := otpauth.ParseMigrationURL(input)
+			if err != nil {
+				t.Errorf("ParseMigrationURL(%q): unexpected error: %v", input, err)
+			} else if diff := cmp.Diff(got, want); diff != "" {
+				t.Errorf("ParseMigrationURL(%q) (-got, +want):\n%s", input, diff)
+			}
+		}
+	}
+}
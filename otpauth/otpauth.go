@@ -15,8 +15,10 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/creachadair/otp"
 )
@@ -25,11 +27,53 @@ const (
 	defaultAlgorithm = "SHA1"
 	defaultDigits    = 6
 	defaultPeriod    = 30
+
+	maxDigits = 10 // the most digits a 31-bit truncated code can fill
 )
 
+// ParseOptions control optional parsing behaviour.
+// A nil *ParseOptions is ready for use and provides strict parsing.
+type ParseOptions struct {
+	// Lenient, if true, relaxes the decoding of migration payloads:
+	// Unspecified (zero) enum values are replaced by the documented defaults,
+	// and unknown enum values are reported as warnings on the Migration
+	// instead of failing the whole payload. Unknown digit codes are kept as
+	// the digit count, since some exporters write the count directly.
+	Lenient bool
+
+	// AllowUnknown, if true, permits otpauth URLs to contain parameters the
+	// parser does not recognize, such as the "image" parameter used by
+	// FreeOTP. Such parameters are retained in the Extra field of the URL.
+	// Otherwise, unknown parameters are reported as errors.
+	AllowUnknown bool
+
+	// Validate, if true, checks each parsed URL with URL.Validate, and also
+	// reports problems that are only visible in the URL string: an HOTP URL
+	// with no counter parameter, and an issuer in the label that conflicts
+	// with the issuer parameter. Problems are reported as a *ValidationError.
+	Validate bool
+
+	// InferPreset, if true, applies the settings of a registered otp.Preset
+	// whose name or alias matches the issuer of a parsed URL, if the URL does
+	// not specify a preset, algorithm, digits, period, or encoder. The
+	// algorithm, digits, period, and encoder of the URL are set from the
+	// preset; the Preset field is not set, so the URL string does not gain a
+	// preset parameter. Presets whose settings cannot be expressed in a URL,
+	// such as a custom hash or formatting function, are not applied.
+	InferPreset bool
+}
+
+func (o *ParseOptions) lenient() bool { return o != nil && o.Lenient }
+
+func (o *ParseOptions) allowUnknown() bool { return o != nil && o.AllowUnknown }
+
+func (o *ParseOptions) validate() bool { return o != nil && o.Validate }
+
+func (o *ParseOptions) inferPreset() bool { return o != nil && o.InferPreset }
+
 // A URL contains the parsed representation of an otpauth URL.
 type URL struct {
-	Type      string // normalized to lowercase, e.g., "totp"
+	Type      string // normalized to lowercase, e.g., "totp" or "yaotp"
 	Issuer    string // also called "provider" in some docs
 	Account   string // without provider prefix
 	RawSecret string // base32-encoded, no padding
@@ -37,6 +81,40 @@ type URL struct {
 	Digits    int    // default is 6
 	Period    int    // in seconds; default is 30
 	Counter   uint64
+	Encoder   string  // normalized to lowercase; "steam" or empty for decimal
+	Preset    string  // the name of an otp.Preset, or empty for none
+	PINLength int     // for yaotp, the length of the PIN; 0 if unknown
+	Extra     []Param // unrecognized parameters, in order of appearance
+}
+
+// A Param is a URL parameter that is not otherwise represented by the fields
+// of a URL, such as a vendor-specific extension.
+type Param struct {
+	Key, Value string
+}
+
+// Param reports the value of the first parameter in u.Extra with the given
+// key, and whether such a parameter was found.
+func (u *URL) Param(key string) (string, bool) {
+	for _, p := range u.Extra {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetParam sets the value of the first parameter in u.Extra with the given
+// key, or adds a new parameter if there is none. The key should not be one
+// of the standard parameters represented by the fields of u.
+func (u *URL) SetParam(key, value string) {
+	for i, p := range u.Extra {
+		if p.Key == key {
+			u.Extra[i].Value = value
+			return
+		}
+	}
+	u.Extra = append(u.Extra, Param{Key: key, Value: value})
 }
 
 // Secret parses the contents of the RawSecret field.
@@ -47,6 +125,180 @@ var sec32 = base32.StdEncoding.WithPadding(base32.NoPadding)
 // SetSecret encodes key as base32 and updates the RawSecret field.
 func (u *URL) SetSecret(key []byte) { u.RawSecret = sec32.EncodeToString(key) }
 
+// Config returns an otp.Config with the key and settings described by u.
+// It reports an error if u has an unknown type, algorithm, or encoder, or if
+// its secret cannot be decoded, or if u.Digits is negative or more than 10.
+// If u.Encoder is "steam", the config formats
+// codes using otp.FormatSteam.
+//
+// If u.Preset is set, the config is constructed from that preset, and the
+// Algorithm, Digits, Period, and Encoder fields of u are ignored, even if they
+// were given explicitly and differ from the preset; only the secret and
+// counter are taken from u. It is an error if the preset is not registered.
+//
+// A yaotp URL requires a PIN, so Config reports an error for it; use
+// YandexConfig instead.
+func (u *URL) Config() (otp.Config, error) {
+	switch u.Type {
+	case "totp", "hotp":
+	case "yaotp":
+		return otp.Config{}, errors.New("yaotp requires a PIN; use YandexConfig")
+	default:
+		return otp.Config{}, fmt.Errorf("unknown type %q", u.Type)
+	}
+	alg := otp.SHA1
+	if u.Algorithm != "" {
+		var err error
+		alg, err = otp.ParseAlgorithm(u.Algorithm)
+		if err != nil {
+			return otp.Config{}, err
+		}
+	}
+	key, err := u.Secret()
+	if err != nil {
+		return otp.Config{}, fmt.Errorf("invalid secret: %w", err)
+	}
+	if u.Preset != "" {
+		p, ok := otp.LookupPreset(u.Preset)
+		if !ok {
+			return otp.Config{}, fmt.Errorf("unknown preset %q", u.Preset)
+		}
+		cfg := p.NewConfig(key)
+		cfg.Counter = u.Counter
+		return cfg, nil
+	}
+	if u.Digits < 0 || u.Digits > maxDigits {
+		return otp.Config{}, fmt.Errorf("%d digits is out of range 1..%d", u.Digits, maxDigits)
+	}
+	cfg := otp.Config{
+		Key:       string(key),
+		Algorithm: alg,
+		Digits:    u.Digits,
+		Counter:   u.Counter,
+	}
+	switch u.Encoder {
+	case "":
+	case "steam":
+		cfg.Format = otp.FormatSteam
+	default:
+		return otp.Config{}, fmt.Errorf("unknown encoder %q", u.Encoder)
+	}
+	if u.Period > 0 {
+		cfg.Period = time.Duration(u.Period) * time.Second
+	}
+	return cfg, nil
+}
+
+// YandexConfig returns an otp.Config that generates Yandex.Key codes for the
+// secret in u and the given PIN, as constructed by otp.YandexConfig. It reports
+// an error if u is not a yaotp URL, if the PIN does not have the length given
+// by u.PINLength (when that is nonzero), if the secret is invalid, or if
+// u.Digits is negative or more than 10.
+func (u *URL) YandexConfig(pin string) (otp.Config, error) {
+	if u.Type != "yaotp" {
+		return otp.Config{}, fmt.Errorf("type %q is not yaotp", u.Type)
+	}
+	if u.PINLength > 0 && len(pin) != u.PINLength {
+		return otp.Config{}, fmt.Errorf("PIN has length %d, want %d", len(pin), u.PINLength)
+	}
+	if u.Digits < 0 || u.Digits > maxDigits {
+		return otp.Config{}, fmt.Errorf("%d digits is out of range 1..%d", u.Digits, maxDigits)
+	}
+	key, err := u.Secret()
+	if err != nil {
+		return otp.Config{}, fmt.Errorf("invalid secret: %w", err)
+	}
+	cfg, err := otp.YandexConfig(key, pin)
+	if err != nil {
+		return otp.Config{}, err
+	}
+	if u.Digits > 0 {
+		cfg.Digits = u.Digits
+	}
+	if u.Period > 0 {
+		cfg.Period = time.Duration(u.Period) * time.Second
+	}
+	return cfg, nil
+}
+
+// NewURL constructs a URL of the given type ("totp" or "hotp") for the
+// specified issuer and account, whose secret and parameters are taken from
+// cfg. The issuer may be empty.
+//
+// It reports an error if cfg has settings that cannot be represented in a
+// URL, namely a custom Hash, TimeStep, or Format function, a Period that is
+// not a whole number of seconds, or a T0 other than the Unix epoch.
+func NewURL(typ, issuer, account string, cfg otp.Config) (*URL, error) {
+	typ = strings.ToLower(typ)
+	switch {
+	case typ != "totp" && typ != "hotp":
+		return nil, fmt.Errorf("unknown type %q", typ)
+	case account == "":
+		return nil, errors.New("empty account name")
+	case !cfg.Algorithm.Valid():
+		return nil, fmt.Errorf("unknown algorithm %v", cfg.Algorithm)
+	case cfg.Hash != nil:
+		return nil, errors.New("custom hash function is not supported")
+	case cfg.TimeStep != nil:
+		return nil, errors.New("custom time step function is not supported")
+	case cfg.Format != nil:
+		return nil, errors.New("custom format function is not supported")
+	case cfg.Period%time.Second != 0:
+		return nil, fmt.Errorf("period %v is not a whole number of seconds", cfg.Period)
+	case !cfg.T0.IsZero() && cfg.T0.UnixNano() != 0:
+		return nil, fmt.Errorf("initial time %v is not supported", cfg.T0)
+	}
+	u := &URL{
+		Type:      typ,
+		Issuer:    issuer,
+		Account:   account,
+		Algorithm: cfg.Algorithm.String(),
+		Digits:    cfg.Digits,
+		Period:    int(cfg.Period / time.Second),
+		Counter:   cfg.Counter,
+	}
+	if u.Digits <= 0 {
+		u.Digits = defaultDigits
+	}
+	if u.Period <= 0 {
+		u.Period = defaultPeriod
+	}
+	u.SetSecret([]byte(cfg.Key))
+	return u, nil
+}
+
+// NewSteamURL constructs a TOTP URL for the specified Steam account using the
+// given secret key, with the settings used by Steam Guard.
+func NewSteamURL(account string, key []byte) *URL {
+	u := &URL{
+		Type:      "totp",
+		Issuer:    "Steam",
+		Account:   account,
+		Algorithm: defaultAlgorithm,
+		Digits:    otp.SteamDigits,
+		Period:    defaultPeriod,
+		Encoder:   "steam",
+	}
+	u.SetSecret(key)
+	return u
+}
+
+// NewBattleNetURL constructs a TOTP URL for a Battle.net authenticator with
+// the given serial number and secret key, with the settings used by
+// Battle.net. The serial number is used as the account name.
+func NewBattleNetURL(serial string, key []byte) *URL {
+	u := &URL{
+		Type:      "totp",
+		Issuer:    "Battle.net",
+		Account:   serial,
+		Algorithm: defaultAlgorithm,
+		Digits:    8,
+		Period:    defaultPeriod,
+	}
+	u.SetSecret(key)
+	return u
+}
+
 // String converts u to a URL in the standard encoding.
 func (u *URL) String() string {
 	var sb strings.Builder
@@ -67,16 +319,28 @@ func (u *URL) String() string {
 	if d := u.Digits; d > 0 && d != defaultDigits {
 		params = append(params, "digits="+strconv.Itoa(d))
 	}
+	if e := u.Encoder; e != "" {
+		params = append(params, "encoder="+queryEscape(strings.ToLower(e)))
+	}
 	if o := u.Issuer; o != "" {
 		params = append(params, "issuer="+queryEscape(o))
 	}
 	if p := u.Period; p > 0 && p != defaultPeriod {
 		params = append(params, "period="+strconv.Itoa(p))
 	}
+	if n := u.PINLength; n > 0 {
+		params = append(params, "pin_length="+strconv.Itoa(n))
+	}
+	if p := u.Preset; p != "" {
+		params = append(params, "preset="+queryEscape(p))
+	}
 	if s := u.RawSecret; s != "" {
 		enc := strings.ToUpper(strings.Join(strings.Fields(strings.TrimRight(s, "=")), ""))
 		params = append(params, "secret="+queryEscape(enc))
 	}
+	for _, p := range u.Extra {
+		params = append(params, queryEscape(p.Key)+"="+queryEscape(p.Value))
+	}
 	if len(params) != 0 {
 		sb.WriteByte('?')
 		sb.WriteString(strings.Join(params, "&"))
@@ -85,13 +349,14 @@ func (u *URL) String() string {
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
-// It expects its input to be a URL in the standard encoding.
+// It expects its input to be a URL in the standard encoding. Unknown
+// parameters are allowed, so that any URL emitted by MarshalText is accepted.
 func (u *URL) UnmarshalText(data []byte) error {
-	p, err := ParseURL(string(data))
+	p, err := (&ParseOptions{AllowUnknown: true}).ParseURL(string(data))
 	if err != nil {
 		return err
 	}
-	*u = *p // a shallow copy is safe, there are no pointers
+	*u = *p // a shallow copy is safe, p is not otherwise shared
 	return nil
 }
 
@@ -133,18 +398,30 @@ func (u *URL) parseLabel(s string) error {
 // The input may omit a scheme, but if present the scheme must be otpauth://.
 // The parser will report an error for invalid syntax, including unknown URL
 // parameters, but does not otherwise validate the results. In particular, the
-// values of the Type and Algorithm fields are not checked.
+// values of the Type and Algorithm fields are not checked. Use URL.Validate or
+// ParseOptions.Validate to check them.
 //
 // Fields of the URL corresponding to unset parameters are populated with
 // default values as described on the URL struct. If a different issuer is set
 // on the label and in the parameters, the parameter takes priority.
-func ParseURL(s string) (*URL, error) {
+//
+// The Steam Guard conventions are recognized: A URL with the parameter
+// encoder=steam, or whose secret has a "steam://" prefix, has its Encoder set
+// to "steam", and its digits default to otp.SteamDigits. Likewise, the digits
+// of a Yandex.Key URL (type yaotp) default to otp.YandexDigits.
+func ParseURL(s string) (*URL, error) { return (*ParseOptions)(nil).ParseURL(s) }
+
+// ParseURL parses s as a URL in the otpauth scheme, as the top-level ParseURL
+// function does, subject to the settings of o.
+func (o *ParseOptions) ParseURL(s string) (*URL, error) {
 	// A scheme is not required, but if present it must be "otpauth".
-	if ps := strings.SplitN(s, "://", 2); len(ps) == 2 {
-		if ps[0] != "otpauth" {
-			return nil, fmt.Errorf("invalid scheme %q", ps[0])
+	// Only consider a "://" before the label, since the parameters may also
+	// contain one (e.g., secret=steam://...).
+	if i := strings.Index(s, "://"); i >= 0 && !strings.ContainsAny(s[:i], "/?") {
+		if s[:i] != "otpauth" {
+			return nil, fmt.Errorf("invalid scheme %q", s[:i])
 		}
-		s = ps[1] // trim scheme prefix
+		s = s[i+3:] // trim scheme prefix
 	}
 
 	// Extract TYPE/LABEL and optional PARAMS.
@@ -171,8 +448,9 @@ func ParseURL(s string) (*URL, error) {
 	if err := out.parseLabel(ps[1]); err != nil {
 		return nil, fmt.Errorf("invalid label: %v", err)
 	}
+	info := parseInfo{labelIssuer: out.Issuer}
 	if params == "" {
-		return out, nil
+		return o.finishParse(out, info)
 	}
 
 	// Parse URL parameters.
@@ -188,14 +466,25 @@ func ParseURL(s string) (*URL, error) {
 
 		// Handle string-valued parameters.
 		if ps[0] == "algorithm" {
-			out.Algorithm = strings.ToUpper(value)
+			out.Algorithm, info.hasAlgorithm = strings.ToUpper(value), true
 			continue
 		} else if ps[0] == "issuer" {
 			out.Issuer = value
 			continue
 		} else if ps[0] == "secret" {
+			// Some tools mark Steam secrets with a "steam://" prefix.
+			if rest, ok := cutPrefixFold(value, "steam://"); ok {
+				out.Encoder = "steam"
+				value = rest
+			}
 			out.RawSecret = value
 			continue
+		} else if ps[0] == "encoder" {
+			out.Encoder = strings.ToLower(value)
+			continue
+		} else if ps[0] == "preset" {
+			out.Preset = value
+			continue
 		}
 
 		// All other valid parameters require an integer argument.
@@ -204,19 +493,91 @@ func ParseURL(s string) (*URL, error) {
 
 		switch ps[0] {
 		case "counter":
-			out.Counter = n
+			out.Counter, info.hasCounter = n, true
 		case "digits":
-			out.Digits = int(n)
+			out.Digits, info.hasDigits = int(n), true
 		case "period":
-			out.Period = int(n)
+			out.Period, info.hasPeriod = int(n), true
+		case "pin_length":
+			out.PINLength = int(n)
 		default:
-			return nil, fmt.Errorf("invalid parameter %q", ps[0])
+			if !o.allowUnknown() {
+				return nil, fmt.Errorf("invalid parameter %q", ps[0])
+			}
+			key, err := url.QueryUnescape(ps[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid parameter: %v", err)
+			}
+			out.Extra = append(out.Extra, Param{Key: key, Value: value})
+			continue
 		}
 		if err != nil {
 			return nil, fmt.Errorf("invalid integer value %q", value)
 		}
 	}
-	return out, nil
+	return o.finishParse(out, info)
+}
+
+// parseInfo records details of a URL string that are not retained by the URL.
+type parseInfo struct {
+	labelIssuer string // the issuer given in the label, if any
+	hasCounter  bool   // whether a counter parameter was present
+	hasDigits   bool   // whether a digits parameter was present
+
+	hasAlgorithm bool // whether an algorithm parameter was present
+	hasPeriod    bool // whether a period parameter was present
+}
+
+// finishParse applies defaults and the post-processing requested by o to a
+// URL parsed from a string.
+func (o *ParseOptions) finishParse(u *URL, info parseInfo) (*URL, error) {
+	// Steam and Yandex codes have different default lengths.
+	if !info.hasDigits {
+		if u.Encoder == "steam" {
+			u.Digits = otp.SteamDigits
+		} else if u.Type == "yaotp" {
+			u.Digits = otp.YandexDigits
+		}
+	}
+	if o.inferPreset() && u.Preset == "" && u.Issuer != "" && (u.Type == "totp" || u.Type == "hotp") &&
+		!info.hasAlgorithm && !info.hasDigits && !info.hasPeriod && u.Encoder == "" {
+		if p, ok := otp.LookupPreset(u.Issuer); ok {
+			applyPreset(u, p.Config)
+		}
+	}
+	return o.checkParsed(u, info)
+}
+
+// applyPreset sets the algorithm, digits, period, and encoder of u from the
+// settings of cfg, if they can be expressed in a URL. Otherwise u is not
+// modified.
+func applyPreset(u *URL, cfg otp.Config) {
+	var enc string
+	switch {
+	case cfg.Hash != nil || cfg.TimeStep != nil || !cfg.T0.IsZero() || cfg.Period%time.Second != 0:
+		return
+	case cfg.Format == nil:
+	case reflect.ValueOf(cfg.Format).Pointer() == reflect.ValueOf(otp.FormatSteam).Pointer():
+		enc = "steam"
+	default:
+		return
+	}
+	u.Algorithm, u.Encoder = cfg.Algorithm.String(), enc
+	u.Digits, u.Period = defaultDigits, defaultPeriod
+	if cfg.Digits > 0 {
+		u.Digits = cfg.Digits
+	}
+	if cfg.Period > 0 {
+		u.Period = int(cfg.Period / time.Second)
+	}
+}
+
+// cutPrefixFold is as strings.CutPrefix, but compares without regard to case.
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
 }
 
 func queryEscape(s string) string {
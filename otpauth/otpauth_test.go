@@ -3,9 +3,12 @@
 package otpauth_test
 
 import (
+	"crypto/sha256"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/creachadair/otp"
 	"github.com/creachadair/otp/otpauth"
 	"github.com/google/go-cmp/cmp"
 )
@@ -205,3 +208,462 @@ func TestParseMigrationURL(t *testing.T) {
 		t.Errorf("Parsed (-got, +want):\n%s", diff)
 	}
 }
+
+func TestConfig(t *testing.T) {
+	u, err := otpauth.ParseURL(`otpauth://totp/ACME:jo?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&algorithm=SHA256&digits=8&period=60`)
+	if err != nil {
+		t.Fatalf("ParseURL: unexpected error: %v", err)
+	}
+	cfg, err := u.Config()
+	if err != nil {
+		t.Fatalf("Config: unexpected error: %v", err)
+	}
+	if cfg.Key != "12345678901234567890" {
+		t.Errorf("Key: got %q, want %q", cfg.Key, "12345678901234567890")
+	}
+	if cfg.Algorithm != otp.SHA256 || cfg.Digits != 8 || cfg.Period != time.Minute {
+		t.Errorf("Config: got %v/%d/%v, want SHA256/8/1m0s", cfg.Algorithm, cfg.Digits, cfg.Period)
+	}
+
+	// The resulting config should be able to generate codes.
+	ref := otp.Config{Key: "12345678901234567890", Algorithm: otp.SHA256, Digits: 8}
+	if got, want := cfg.TOTPAt(time.Unix(120, 0)), ref.HOTP(2); got != want {
+		t.Errorf("TOTPAt: got %q, want %q", got, want)
+	}
+
+	// Round trip back to a URL.
+	v, err := otpauth.NewURL("totp", "ACME", "jo", cfg)
+	if err != nil {
+		t.Fatalf("NewURL: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(v, u); diff != "" {
+		t.Errorf("NewURL (-got, +want):\n%s", diff)
+	}
+
+	t.Run("HOTP", func(t *testing.T) {
+		u := &otpauth.URL{Type: "hotp", Account: "x", RawSecret: "MFRGGZDFMZTWQ2LK", Counter: 17}
+		cfg, err := u.Config()
+		if err != nil {
+			t.Fatalf("Config: unexpected error: %v", err)
+		}
+		if cfg.Counter != 17 || cfg.Algorithm != otp.SHA1 || cfg.Period != 0 {
+			t.Errorf("Config: got counter %d, algorithm %v, period %v", cfg.Counter, cfg.Algorithm, cfg.Period)
+		}
+	})
+
+	t.Run("ConfigErrors", func(t *testing.T) {
+		for _, u := range []*otpauth.URL{
+			{Type: "motp", Account: "x"},
+			{Type: "totp", Account: "x", Algorithm: "CRC32"},
+			{Type: "totp", Account: "x", RawSecret: "not base32!"},
+			{Type: "totp", Account: "x", RawSecret: "JBSWY3DPEHPK3PXP", Digits: 40},
+			{Type: "totp", Account: "x", RawSecret: "JBSWY3DPEHPK3PXP", Digits: 11},
+			{Type: "totp", Account: "x", RawSecret: "JBSWY3DPEHPK3PXP", Digits: -1},
+		} {
+			if cfg, err := u.Config(); err == nil {
+				t.Errorf("Config(%v): got %+v, want error", u, cfg)
+			}
+		}
+
+		// An out-of-range digits parameter is accepted by ParseURL, but the
+		// URL must not produce a config that panics when generating codes.
+		u, err := otpauth.ParseURL("otpauth://totp/x?secret=JBSWY3DPEHPK3PXP&digits=40")
+		if err != nil {
+			t.Fatalf("ParseURL: unexpected error: %v", err)
+		}
+		if cfg, err := u.Config(); err == nil {
+			t.Errorf("Config(%v): got %+v, want error", u, cfg)
+		}
+		u.Digits = 10
+		if _, err := u.Config(); err != nil {
+			t.Errorf("Config(%v): unexpected error: %v", u, err)
+		}
+	})
+
+	t.Run("NewURLErrors", func(t *testing.T) {
+		tests := []struct {
+			typ, account string
+			cfg          otp.Config
+		}{
+			{"motp", "x", otp.Config{}},
+			{"totp", "", otp.Config{}},
+			{"totp", "x", otp.Config{Algorithm: 100}},
+			{"totp", "x", otp.Config{Hash: sha256.New}},
+			{"totp", "x", otp.Config{TimeStep: func() uint64 { return 1 }}},
+			{"totp", "x", otp.Config{Format: otp.FormatAlphabet("abc")}},
+			{"totp", "x", otp.Config{Period: 1500 * time.Millisecond}},
+			{"totp", "x", otp.Config{T0: time.Unix(100, 0)}},
+		}
+		for _, tc := range tests {
+			if u, err := otpauth.NewURL(tc.typ, "", tc.account, tc.cfg); err == nil {
+				t.Errorf("NewURL(%q, %q, %+v): got %v, want error", tc.typ, tc.account, tc.cfg, u)
+			}
+		}
+	})
+}
+
+func TestUnknownParams(t *testing.T) {
+	const input = `otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&image=https%3A%2F%2Fexample.com%2Fa.png&lock=false&digits=8&color=%23ff0000&icon=x`
+
+	if u, err := otpauth.ParseURL(input); err == nil {
+		t.Fatalf("ParseURL (strict): got %v, want error", u)
+	}
+
+	opts := &otpauth.ParseOptions{AllowUnknown: true}
+	u, err := opts.ParseURL(input)
+	if err != nil {
+		t.Fatalf("ParseURL: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(u, &otpauth.URL{
+		Type: "totp", Issuer: "Example", Account: "alice", RawSecret: "JBSWY3DPEHPK3PXP",
+		Algorithm: "SHA1", Digits: 8, Period: 30,
+		Extra: []otpauth.Param{
+			{"image", "https://example.com/a.png"},
+			{"lock", "false"},
+			{"color", "#ff0000"},
+			{"icon", "x"},
+		},
+	}); diff != "" {
+		t.Errorf("ParseURL (-got, +want):\n%s", diff)
+	}
+
+	if v, ok := u.Param("lock"); !ok || v != "false" {
+		t.Errorf(`Param("lock"): got (%q, %v), want ("false", true)`, v, ok)
+	}
+	if v, ok := u.Param("nonesuch"); ok {
+		t.Errorf(`Param("nonesuch"): got (%q, %v), want ("", false)`, v, ok)
+	}
+	u.SetParam("lock", "true")
+	u.SetParam("group", "work")
+
+	const want = `otpauth://totp/Example:alice?digits=8&issuer=Example&secret=JBSWY3DPEHPK3PXP` +
+		`&image=https%3A%2F%2Fexample.com%2Fa.png&lock=true&color=%23ff0000&icon=x&group=work`
+	if got := u.String(); got != want {
+		t.Errorf("String:\n got %q\nwant %q", got, want)
+	}
+
+	// The encoded URL should round-trip through text marshaling.
+	var v otpauth.URL
+	if err := v.UnmarshalText([]byte(u.String())); err != nil {
+		t.Fatalf("UnmarshalText: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&v, u); diff != "" {
+		t.Errorf("UnmarshalText (-got, +want):\n%s", diff)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // 20 bytes
+
+	t.Run("Valid", func(t *testing.T) {
+		for _, s := range []string{
+			"otpauth://totp/alice?secret=" + secret,
+			"otpauth://totp/Example:alice?issuer=Example&algorithm=SHA256&digits=8&secret=" + secret,
+			"otpauth://hotp/bob?counter=0&digits=10&secret=" + secret,
+		} {
+			opts := &otpauth.ParseOptions{Validate: true}
+			u, err := opts.ParseURL(s)
+			if err != nil {
+				t.Errorf("ParseURL %q: unexpected error: %v", s, err)
+				continue
+			}
+			if err := u.Validate(); err != nil {
+				t.Errorf("Validate %q: unexpected error: %v", s, err)
+			}
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		tests := []struct {
+			input  string
+			fields []string
+		}{
+			{"otpauth://motp/alice?secret=" + secret, []string{"type"}},
+			{"otpauth://totp/alice?algorithm=SHA384&secret=" + secret, []string{"algorithm"}},
+			{"otpauth://totp/alice?digits=4&secret=" + secret, []string{"digits"}},
+			{"otpauth://totp/alice?digits=12&secret=" + secret, []string{"digits"}},
+			{"otpauth://totp/alice?period=0&secret=" + secret, []string{"period"}},
+			{"otpauth://totp/alice", []string{"secret"}},
+			{"otpauth://totp/alice?secret=JBSWY3DPEHPK3PXP", []string{"secret"}},
+			{"otpauth://totp/alice?secret=NOT*BASE32", []string{"secret"}},
+			{"otpauth://hotp/alice?secret=" + secret, []string{"counter"}},
+			{"otpauth://totp/Foo:alice?issuer=Bar&secret=" + secret, []string{"issuer"}},
+			{"otpauth://hotp/Foo:alice?issuer=Bar&digits=5&period=0&algorithm=x",
+				[]string{"algorithm", "digits", "secret", "counter", "issuer"}},
+		}
+		for _, tc := range tests {
+			// Without validation, parsing succeeds.
+			if _, err := otpauth.ParseURL(tc.input); err != nil {
+				t.Errorf("ParseURL %q: unexpected error: %v", tc.input, err)
+				continue
+			}
+
+			opts := &otpauth.ParseOptions{Validate: true}
+			u, err := opts.ParseURL(tc.input)
+			if err == nil {
+				t.Errorf("ParseURL %q: got %v, want error", tc.input, u)
+				continue
+			}
+			verr, ok := err.(*otpauth.ValidationError)
+			if !ok {
+				t.Errorf("ParseURL %q: got error %[2]T (%[2]v), want *ValidationError", tc.input, err)
+				continue
+			}
+			var got []string
+			for _, fe := range verr.Errors {
+				got = append(got, fe.Field)
+			}
+			if diff := cmp.Diff(got, tc.fields); diff != "" {
+				t.Errorf("ParseURL %q fields (-got, +want):\n%s", tc.input, diff)
+			}
+		}
+	})
+
+	t.Run("Fields", func(t *testing.T) {
+		// A zero period is invalid for TOTP, but irrelevant for HOTP.
+		u := &otpauth.URL{Type: "hotp", Account: "alice", RawSecret: secret}
+		if err := u.Validate(); err != nil {
+			t.Errorf("Validate hotp: unexpected error: %v", err)
+		}
+		u.Type = "totp"
+		if err := u.Validate(); err == nil {
+			t.Error("Validate totp: got nil, want error")
+		}
+	})
+}
+
+func TestSteam(t *testing.T) {
+	const secret = "CQKQQEQRAAR777X5"
+	want := &otpauth.URL{
+		Type: "totp", Issuer: "Steam", Account: "alice", RawSecret: secret,
+		Algorithm: "SHA1", Digits: 5, Period: 30, Encoder: "steam",
+	}
+
+	for _, s := range []string{
+		"otpauth://totp/Steam:alice?issuer=Steam&secret=" + secret + "&encoder=steam",
+		"otpauth://totp/Steam:alice?issuer=Steam&encoder=STEAM&secret=" + secret,
+		"otpauth://totp/Steam:alice?issuer=Steam&secret=steam://" + secret,
+		"totp/Steam:alice?secret=Steam://" + secret,
+	} {
+		u, err := otpauth.ParseURL(s)
+		if err != nil {
+			t.Errorf("ParseURL %q: unexpected error: %v", s, err)
+			continue
+		}
+		if diff := cmp.Diff(u, want); diff != "" {
+			t.Errorf("ParseURL %q (-got, +want):\n%s", s, diff)
+		}
+	}
+
+	key, err := otp.ParseKey(secret)
+	if err != nil {
+		t.Fatalf("ParseKey: unexpected error: %v", err)
+	}
+	u := otpauth.NewSteamURL("alice", key)
+	if diff := cmp.Diff(u, want); diff != "" {
+		t.Errorf("NewSteamURL (-got, +want):\n%s", diff)
+	}
+	const wantURL = "otpauth://totp/Steam:alice?digits=5&encoder=steam&issuer=Steam&secret=" + secret
+	if got := u.String(); got != wantURL {
+		t.Errorf("String:\n got %q\nwant %q", got, wantURL)
+	}
+	if err := u.Validate(); err == nil {
+		// The secret is shorter than MinSecretLength, but the settings are OK.
+		t.Error("Validate: got nil, want error")
+	} else if verr := err.(*otpauth.ValidationError); len(verr.Errors) != 1 || verr.Errors[0].Field != "secret" {
+		t.Errorf("Validate: got %v, want only a secret error", err)
+	}
+
+	cfg, err := u.Config()
+	if err != nil {
+		t.Fatalf("Config: unexpected error: %v", err)
+	}
+	// Reference code from the ValvePython/steam library. The secret is
+	// "superdupersecret".
+	cfg.Key = "superdupersecret"
+	if got, want := cfg.TOTPAt(time.Unix(3000030, 0)), "YRGQJ"; got != want {
+		t.Errorf("TOTP: got %q, want %q", got, want)
+	}
+
+	u.Encoder = "bogus"
+	if cfg, err := u.Config(); err == nil {
+		t.Errorf("Config: got %+v, want error", cfg)
+	}
+}
+
+func TestPreset(t *testing.T) {
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	const input = "otpauth://totp/Blizzard:alice?secret=" + secret
+
+	// Without inference, the issuer does not select a preset.
+	u, err := otpauth.ParseURL(input)
+	if err != nil {
+		t.Fatalf("ParseURL: unexpected error: %v", err)
+	} else if u.Preset != "" {
+		t.Errorf("ParseURL: got preset %q, want none", u.Preset)
+	}
+
+	// With inference, the issuer selects the preset settings, but the preset
+	// is not recorded in the URL.
+	opts := &otpauth.ParseOptions{InferPreset: true}
+	u, err = opts.ParseURL(input)
+	if err != nil {
+		t.Fatalf("ParseURL: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(u, &otpauth.URL{
+		Type: "totp", Issuer: "Blizzard", Account: "alice", RawSecret: secret,
+		Algorithm: "SHA1", Digits: 8, Period: 30,
+	}); diff != "" {
+		t.Errorf("ParseURL (-got, +want):\n%s", diff)
+	}
+	const want = "otpauth://totp/Blizzard:alice?digits=8&issuer=Blizzard&secret=" + secret
+	if got := u.String(); got != want {
+		t.Errorf("String:\n got %q\nwant %q", got, want)
+	}
+
+	// Explicit settings in the URL disable inference.
+	for _, param := range []string{"algorithm=SHA256", "digits=6", "period=60", "encoder=steam"} {
+		v, err := opts.ParseURL(input + "&" + param)
+		if err != nil {
+			t.Fatalf("ParseURL: unexpected error: %v", err)
+		}
+		w, err := otpauth.ParseURL(input + "&" + param)
+		if err != nil {
+			t.Fatalf("ParseURL: unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(v, w); diff != "" {
+			t.Errorf("ParseURL with %s (-got, +want):\n%s", param, diff)
+		}
+	}
+	g, err := opts.ParseURL("otpauth://totp/Google:me?issuer=Google&algorithm=SHA256&digits=8&secret=" + secret)
+	if err != nil {
+		t.Fatalf("ParseURL: unexpected error: %v", err)
+	}
+	cfg, err := g.Config()
+	if err != nil {
+		t.Fatalf("Config: unexpected error: %v", err)
+	}
+	if cfg.Algorithm != otp.SHA256 || cfg.Digits != 8 {
+		t.Errorf("Config: got %v/%d, want SHA256/8", cfg.Algorithm, cfg.Digits)
+	}
+
+	// An inferred Steam preset selects the Steam encoder.
+	st, err := opts.ParseURL("otpauth://totp/Steam:gamer?secret=ON2XAZLSMR2XAZLSONSWG4TFOQ")
+	if err != nil {
+		t.Fatalf("ParseURL: unexpected error: %v", err)
+	}
+	if st.Encoder != "steam" || st.Digits != 5 || st.Preset != "" {
+		t.Errorf("ParseURL: got %+v, want Steam settings", st)
+	}
+	cfg, err = st.Config()
+	if err != nil {
+		t.Fatalf("Config: unexpected error: %v", err)
+	}
+	if got, want := cfg.TOTPAt(time.Unix(3000030, 0)), "YRGQJ"; got != want {
+		t.Errorf("Steam TOTP: got %q, want %q", got, want)
+	}
+
+	// An explicit preset takes priority over the issuer.
+	v, err := opts.ParseURL(input + "&preset=authy")
+	if err != nil {
+		t.Fatalf("ParseURL: unexpected error: %v", err)
+	} else if v.Preset != "authy" {
+		t.Errorf("ParseURL: got preset %q, want authy", v.Preset)
+	}
+	cfg, err = v.Config()
+	if err != nil {
+		t.Fatalf("Config: unexpected error: %v", err)
+	}
+	if cfg.Digits != 7 || cfg.Period != 10*time.Second || cfg.Key != "12345678901234567890" {
+		t.Errorf("Config: got %+v, want Authy settings", cfg)
+	}
+
+	// Explicit settings in the URL that differ from an explicit preset are
+	// ignored.
+	w, err := opts.ParseURL(input + "&preset=authy&algorithm=SHA256&digits=8&period=60")
+	if err != nil {
+		t.Fatalf("ParseURL: unexpected error: %v", err)
+	}
+	cfg, err = w.Config()
+	if err != nil {
+		t.Fatalf("Config: unexpected error: %v", err)
+	}
+	if cfg.Algorithm != otp.SHA1 || cfg.Digits != 7 || cfg.Period != 10*time.Second {
+		t.Errorf("Config: got %+v, want Authy settings", cfg)
+	}
+
+	u.Preset = "nonesuch"
+	if cfg, err := u.Config(); err == nil {
+		t.Errorf("Config: got %+v, want error", cfg)
+	}
+	if err := u.Validate(); err == nil {
+		t.Error("Validate: got nil, want error")
+	}
+}
+
+func TestBattleNet(t *testing.T) {
+	key := []byte("12345678901234567890")
+	u := otpauth.NewBattleNetURL("US-1234-5678-9012", key)
+
+	const want = "otpauth://totp/Battle.net:US-1234-5678-9012?digits=8&issuer=Battle.net" +
+		"&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	if got := u.String(); got != want {
+		t.Errorf("String:\n got %q\nwant %q", got, want)
+	}
+	if err := u.Validate(); err != nil {
+		t.Errorf("Validate: unexpected error: %v", err)
+	}
+
+	cfg, err := u.Config()
+	if err != nil {
+		t.Fatalf("Config: unexpected error: %v", err)
+	}
+	ref, _ := otp.LookupPreset("Battle.net")
+	if cfg.Digits != ref.Config.Digits || cfg.Period != ref.Config.Period || cfg.Algorithm != ref.Config.Algorithm {
+		t.Errorf("Config: got %+v, want Battle.net preset settings", cfg)
+	}
+}
+
+func TestYandex(t *testing.T) {
+	const input = "otpauth://yaotp/alice@yandex.ru?secret=LA2V6KMCGYMWWVEW64RNP3JA3IAAAAAAHTSG4HRZPI&pin_length=4"
+	opts := &otpauth.ParseOptions{Validate: true}
+	u, err := opts.ParseURL(input)
+	if err != nil {
+		t.Fatalf("ParseURL: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(u, &otpauth.URL{
+		Type: "yaotp", Account: "alice@yandex.ru",
+		RawSecret: "LA2V6KMCGYMWWVEW64RNP3JA3IAAAAAAHTSG4HRZPI",
+		Algorithm: "SHA1", Digits: 8, Period: 30, PINLength: 4,
+	}); diff != "" {
+		t.Errorf("ParseURL (-got, +want):\n%s", diff)
+	}
+	const want = "otpauth://yaotp/alice@yandex.ru?digits=8&pin_length=4&secret=LA2V6KMCGYMWWVEW64RNP3JA3IAAAAAAHTSG4HRZPI"
+	if got := u.String(); got != want {
+		t.Errorf("String:\n got %q\nwant %q", got, want)
+	}
+
+	if cfg, err := u.Config(); err == nil {
+		t.Errorf("Config: got %+v, want error", cfg)
+	}
+	if cfg, err := u.YandexConfig("123"); err == nil {
+		t.Errorf("YandexConfig (short PIN): got %+v, want error", cfg)
+	}
+	long := *u
+	long.Digits = 40
+	if cfg, err := long.YandexConfig("7586"); err == nil {
+		t.Errorf("YandexConfig (40 digits): got %+v, want error", cfg)
+	}
+	cfg, err := u.YandexConfig("7586")
+	if err != nil {
+		t.Fatalf("YandexConfig: unexpected error: %v", err)
+	}
+	if got, want := cfg.TOTPAt(time.Unix(1581064020, 0)), "oactmacq"; got != want {
+		t.Errorf("TOTPAt: got %q, want %q", got, want)
+	}
+
+	v := &otpauth.URL{Type: "totp", Account: "x", RawSecret: u.RawSecret}
+	if cfg, err := v.YandexConfig("7586"); err == nil {
+		t.Errorf("YandexConfig (totp): got %+v, want error", cfg)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otpauth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/creachadair/otp"
+)
+
+// MinSecretLength is the minimum length in bytes of a secret accepted by
+// Validate. RFC 4226 requires a shared secret of at least 128 bits.
+const MinSecretLength = 16
+
+// A FieldError describes a problem with one field of a URL.
+type FieldError struct {
+	Field string // the URL parameter or component, e.g., "digits"
+	Err   error  // the problem with the field
+}
+
+// Error satisfies the error interface.
+func (e *FieldError) Error() string { return e.Field + ": " + e.Err.Error() }
+
+// Unwrap supports error wrapping.
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// A ValidationError is reported by Validate, and collects all the problems
+// found with a URL.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+// Error satisfies the error interface.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "invalid URL: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap supports error wrapping.
+func (e *ValidationError) Unwrap() []error {
+	out := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		out[i] = fe
+	}
+	return out
+}
+
+func (e *ValidationError) add(field, msg string, args ...any) {
+	e.Errors = append(e.Errors, &FieldError{Field: field, Err: fmt.Errorf(msg, args...)})
+}
+
+func (e *ValidationError) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate checks that u describes a usable OTP configuration. If not, it
+// reports a *ValidationError describing each problem found. Fields that are
+// zero are treated as having their default values, except that a time-based
+// (totp or yaotp) URL must have a positive period.
+//
+// Some problems can only be detected while parsing a URL string, such as a
+// missing HOTP counter. To check for those, parse with ParseOptions.Validate.
+func (u *URL) Validate() error {
+	var verr ValidationError
+	u.validate(&verr)
+	return verr.errOrNil()
+}
+
+func (u *URL) validate(verr *ValidationError) {
+	typ := strings.ToLower(u.Type)
+	switch typ {
+	case "totp", "hotp", "yaotp":
+	case "":
+		verr.add("type", "missing type")
+	default:
+		verr.add("type", "unknown type %q", u.Type)
+	}
+	if u.Preset != "" {
+		if _, ok := otp.LookupPreset(u.Preset); !ok {
+			verr.add("preset", "unknown preset %q", u.Preset)
+		}
+	}
+	if u.Account == "" {
+		verr.add("account", "missing account name")
+	}
+	if u.Algorithm != "" {
+		if _, err := otp.ParseAlgorithm(u.Algorithm); err != nil {
+			verr.add("algorithm", "unsupported algorithm %q", u.Algorithm)
+		}
+	}
+	minDigits := 6
+	switch u.Encoder {
+	case "":
+	case "steam":
+		minDigits = otp.SteamDigits
+	default:
+		verr.add("encoder", "unknown encoder %q", u.Encoder)
+	}
+	if d := u.Digits; d != 0 && (d < minDigits || d > maxDigits) {
+		verr.add("digits", "%d digits is out of range %d..%d", d, minDigits, maxDigits)
+	}
+	if (typ == "totp" || typ == "yaotp") && u.Period <= 0 {
+		verr.add("period", "period must be positive")
+	}
+	if u.RawSecret == "" {
+		verr.add("secret", "missing secret")
+	} else if key, err := u.Secret(); err != nil {
+		verr.add("secret", "invalid base32: %v", err)
+	} else if len(key) < MinSecretLength {
+		verr.add("secret", "secret is %d bytes, must be at least %d", len(key), MinSecretLength)
+	}
+}
+
+// checkParsed applies the validation requested by o, if any, to a URL parsed
+// from a string.
+func (o *ParseOptions) checkParsed(u *URL, info parseInfo) (*URL, error) {
+	if !o.validate() {
+		return u, nil
+	}
+	var verr ValidationError
+	u.validate(&verr)
+	if u.Type == "hotp" && !info.hasCounter {
+		verr.add("counter", "missing counter for hotp")
+	}
+	if info.labelIssuer != "" && info.labelIssuer != u.Issuer {
+		verr.add("issuer", "label issuer %q does not match issuer parameter %q", info.labelIssuer, u.Issuer)
+	}
+	if err := verr.errOrNil(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// A Preset is a named Config template with the settings used by a particular
+// service or authenticator, such as Steam.
+type Preset struct {
+	Name    string   // the canonical name, e.g., "Battle.net"
+	Aliases []string // other names for the preset, e.g., "Blizzard"
+
+	// Config is the template for the preset. Its Key and Counter fields are
+	// ignored, and are zero in presets returned by LookupPreset.
+	Config Config
+}
+
+// NewConfig returns a copy of the preset's Config template with the given key.
+func (p Preset) NewConfig(key []byte) Config {
+	c := p.Config
+	c.Key = string(key)
+	return c
+}
+
+var presets = struct {
+	μ     sync.Mutex
+	byKey map[string]*Preset // normalized name or alias → preset
+	names []string           // canonical names, in order of registration
+}{byKey: make(map[string]*Preset)}
+
+func init() {
+	for _, p := range []Preset{{
+		Name:   "Google",
+		Config: Config{Algorithm: SHA1, Digits: 6, Period: 30 * time.Second},
+	}, {
+		Name:   "Authy",
+		Config: Config{Algorithm: SHA1, Digits: 7, Period: 10 * time.Second},
+	}, {
+		Name:    "Battle.net",
+		Aliases: []string{"Blizzard"},
+		Config:  Config{Algorithm: SHA1, Digits: 8, Period: 30 * time.Second},
+	}, {
+		Name:   "Steam",
+		Config: SteamConfig(),
+	}} {
+		if err := RegisterPreset(p); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// presetKey normalizes a preset name for lookup. Case is not significant, and
+// characters other than letters and digits are ignored, so that "Battle.net"
+// and "battlenet" are equivalent.
+func presetKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// RegisterPreset adds p to the registry of presets, so that it can be found by
+// LookupPreset. It reports an error if p has no name, if its name or one of
+// its aliases is already registered, or if its Config is not usable.
+//
+// The built-in presets are "Google" (SHA-1, 6 digits, 30 seconds), "Authy"
+// (SHA-1, 7 digits, 10 seconds), "Battle.net" (SHA-1, 8 digits, 30 seconds),
+// and "Steam" (see SteamConfig).
+func RegisterPreset(p Preset) error {
+	if presetKey(p.Name) == "" {
+		return errors.New("empty preset name")
+	}
+	if p.Config.Hash == nil && !p.Config.Algorithm.Valid() {
+		return fmt.Errorf("preset %q: unknown algorithm %v", p.Name, p.Config.Algorithm)
+	}
+	p.Aliases = slices.Clone(p.Aliases)
+	p.Config.Key, p.Config.Counter = "", 0
+
+	presets.μ.Lock()
+	defer presets.μ.Unlock()
+	keys := []string{presetKey(p.Name)}
+	for _, a := range p.Aliases {
+		keys = append(keys, presetKey(a))
+	}
+	for _, k := range keys {
+		if k == "" {
+			return fmt.Errorf("preset %q: empty alias", p.Name)
+		} else if old, ok := presets.byKey[k]; ok {
+			return fmt.Errorf("preset %q: name conflicts with preset %q", p.Name, old.Name)
+		}
+	}
+	for _, k := range keys {
+		presets.byKey[k] = &p
+	}
+	presets.names = append(presets.names, p.Name)
+	return nil
+}
+
+// unregisterPreset removes the preset with the given canonical name and its
+// aliases from the registry, if it is registered. It is used by tests.
+func unregisterPreset(name string) {
+	presets.μ.Lock()
+	defer presets.μ.Unlock()
+	p, ok := presets.byKey[presetKey(name)]
+	if !ok || p.Name != name {
+		return
+	}
+	delete(presets.byKey, presetKey(p.Name))
+	for _, a := range p.Aliases {
+		delete(presets.byKey, presetKey(a))
+	}
+	presets.names = slices.DeleteFunc(presets.names, func(s string) bool { return s == name })
+}
+
+// LookupPreset reports the preset registered under the given name or alias,
+// and whether it was found. Names are compared without regard to case, and
+// characters other than letters and digits are ignored.
+func LookupPreset(name string) (Preset, bool) {
+	presets.μ.Lock()
+	defer presets.μ.Unlock()
+	p, ok := presets.byKey[presetKey(name)]
+	if !ok {
+		return Preset{}, false
+	}
+	out := *p
+	out.Aliases = slices.Clone(p.Aliases)
+	return out, true
+}
+
+// PresetNames returns the canonical names of all registered presets, in
+// lexicographic order.
+func PresetNames() []string {
+	presets.μ.Lock()
+	defer presets.μ.Unlock()
+	out := slices.Clone(presets.names)
+	slices.Sort(out)
+	return out
+}
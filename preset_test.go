@@ -0,0 +1,93 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/creachadair/otp"
+)
+
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		alg    otp.Algorithm
+		digits int
+		period time.Duration
+	}{
+		{"google", "Google", otp.SHA1, 6, 30 * time.Second},
+		{"Authy", "Authy", otp.SHA1, 7, 10 * time.Second},
+		{"Battle.net", "Battle.net", otp.SHA1, 8, 30 * time.Second},
+		{"battlenet", "Battle.net", otp.SHA1, 8, 30 * time.Second},
+		{"BLIZZARD", "Battle.net", otp.SHA1, 8, 30 * time.Second},
+		{"Steam", "Steam", otp.SHA1, 5, 30 * time.Second},
+	}
+	for _, tc := range tests {
+		p, ok := otp.LookupPreset(tc.name)
+		if !ok {
+			t.Errorf("LookupPreset(%q): not found", tc.name)
+			continue
+		}
+		if p.Name != tc.want {
+			t.Errorf("LookupPreset(%q): got name %q, want %q", tc.name, p.Name, tc.want)
+		}
+		c := p.Config
+		if c.Algorithm != tc.alg || c.Digits != tc.digits || c.Period != tc.period {
+			t.Errorf("LookupPreset(%q): got %v/%d/%v, want %v/%d/%v", tc.name,
+				c.Algorithm, c.Digits, c.Period, tc.alg, tc.digits, tc.period)
+		}
+	}
+	if p, ok := otp.LookupPreset("nonesuch"); ok {
+		t.Errorf("LookupPreset(nonesuch): got %+v, want not found", p)
+	}
+
+	// The Steam preset should produce Steam codes.
+	steam, _ := otp.LookupPreset("steam")
+	cfg := steam.NewConfig([]byte("superdupersecret"))
+	if got, want := cfg.TOTPAt(time.Unix(3000030, 0)), "YRGQJ"; got != want {
+		t.Errorf("Steam TOTP: got %q, want %q", got, want)
+	}
+
+	t.Run("Register", func(t *testing.T) {
+		// The registry is global, so remove the test preset afterward to allow
+		// the test to be run repeatedly.
+		t.Cleanup(func() { otp.UnregisterPreset("Test Service") })
+		if err := otp.RegisterPreset(otp.Preset{
+			Name:    "Test Service",
+			Aliases: []string{"testsvc"},
+			Config:  otp.Config{Algorithm: otp.SHA256, Digits: 8, Key: "ignored"},
+		}); err != nil {
+			t.Fatalf("RegisterPreset: unexpected error: %v", err)
+		}
+		p, ok := otp.LookupPreset("TESTSVC")
+		if !ok || p.Name != "Test Service" || p.Config.Algorithm != otp.SHA256 || p.Config.Key != "" {
+			t.Errorf("LookupPreset(TESTSVC): got %+v, %v", p, ok)
+		}
+		if names := otp.PresetNames(); !slices.Contains(names, "Test Service") || !slices.IsSorted(names) {
+			t.Errorf("PresetNames: got %q", names)
+		}
+
+		for _, bad := range []otp.Preset{
+			{},                     // no name
+			{Name: "..."},          // no usable name
+			{Name: "test-service"}, // duplicate name
+			{Name: "Other", Aliases: []string{"STEAM"}}, // duplicate alias
+			{Name: "Other", Config: otp.Config{Algorithm: -1}},
+		} {
+			if err := otp.RegisterPreset(bad); err == nil {
+				t.Errorf("RegisterPreset(%+v): got nil, want error", bad)
+			}
+		}
+
+		otp.UnregisterPreset("Test Service")
+		if p, ok := otp.LookupPreset("testsvc"); ok {
+			t.Errorf("LookupPreset after unregister: got %+v, want not found", p)
+		}
+		if names := otp.PresetNames(); slices.Contains(names, "Test Service") {
+			t.Errorf("PresetNames after unregister: got %q", names)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package pskc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// A keyring holds the keys used to decrypt or encrypt the values of a
+// container.
+type keyring struct {
+	encKey []byte           // the AES encryption key
+	macKey []byte           // the MAC key; nil if values are not authenticated
+	macAlg func() hash.Hash // the MAC hash function
+}
+
+// newKeyring constructs the keyring for decrypting the values of c, using
+// the secrets provided by opts. It returns nil if c does not specify an
+// encryption key.
+func newKeyring(c *xmlContainer, opts *Options) (*keyring, error) {
+	ek := c.EncryptionKey
+	if ek == nil {
+		return nil, nil
+	}
+	kr := new(keyring)
+	switch {
+	case ek.DerivedKey != nil:
+		dm := ek.DerivedKey.Method
+		if dm.Algorithm != algPBKDF2 || dm.Params == nil {
+			return nil, fmt.Errorf("unsupported key derivation %q", dm.Algorithm)
+		}
+		if opts.password() == "" {
+			return nil, errors.New("container is encrypted with a password")
+		}
+		key, err := deriveKey(dm.Params, opts.password())
+		if err != nil {
+			return nil, err
+		}
+		kr.encKey = key
+	default:
+		if len(opts.preSharedKey()) == 0 {
+			return nil, fmt.Errorf("container is encrypted with pre-shared key %q", ek.KeyName)
+		}
+		kr.encKey = opts.preSharedKey()
+	}
+
+	if mm := c.MACMethod; mm != nil {
+		switch mm.Algorithm {
+		case algHMACSHA1:
+			kr.macAlg = sha1.New
+		case algHMACSHA256:
+			kr.macAlg = sha256.New
+		default:
+			return nil, fmt.Errorf("unsupported MAC algorithm %q", mm.Algorithm)
+		}
+		if mm.MACKey == nil {
+			return nil, errors.New("missing MAC key")
+		}
+		mk, err := kr.decrypt(mm.MACKey)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting MAC key: %w", err)
+		}
+		kr.macKey = mk
+	}
+	return kr, nil
+}
+
+// maxIterations is the largest PBKDF2 iteration count accepted. The document
+// is untrusted input, so this bounds the time spent deriving a key before the
+// password can be checked.
+const maxIterations = 10_000_000
+
+func deriveKey(p *xmlPBKDF2Params, password string) ([]byte, error) {
+	salt, err := decodeBase64(p.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	prf := sha1.New
+	if p.PRF != nil && p.PRF.Algorithm != "" {
+		switch p.PRF.Algorithm {
+		case algHMACSHA1:
+		case algHMACSHA256:
+			prf = sha256.New
+		default:
+			return nil, fmt.Errorf("unsupported PBKDF2 PRF %q", p.PRF.Algorithm)
+		}
+	}
+	if p.IterationCount <= 0 || p.IterationCount > maxIterations {
+		return nil, fmt.Errorf("PBKDF2 iteration count %d is out of range 1..%d", p.IterationCount, maxIterations)
+	}
+	if aesAlgorithm(p.KeyLength) == "" {
+		return nil, fmt.Errorf("PBKDF2 key length %d, want 16, 24, or 32", p.KeyLength)
+	}
+	return pbkdf2.Key(prf, password, salt, p.IterationCount, p.KeyLength)
+}
+
+// decrypt decrypts an encrypted value with the encryption key.
+func (kr *keyring) decrypt(ev *xmlEncrypted) ([]byte, error) {
+	if want := aesKeyLength(ev.Method.Algorithm); want == 0 {
+		return nil, fmt.Errorf("unsupported encryption %q", ev.Method.Algorithm)
+	} else if len(kr.encKey) != want {
+		return nil, fmt.Errorf("encryption key has length %d, want %d", len(kr.encKey), want)
+	}
+	raw, err := decodeBase64(ev.CipherData.CipherValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cipher value: %w", err)
+	}
+	if len(raw) < 2*aes.BlockSize || len(raw)%aes.BlockSize != 0 {
+		return nil, errors.New("invalid cipher value length")
+	}
+	block, err := aes.NewCipher(kr.encKey)
+	if err != nil {
+		return nil, err
+	}
+	iv, ct := raw[:aes.BlockSize], raw[aes.BlockSize:]
+	out := make([]byte, len(ct))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, ct)
+
+	// Remove PKCS #7 padding.
+	n := int(out[len(out)-1])
+	if n == 0 || n > aes.BlockSize || !bytes.Equal(out[len(out)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, errors.New("invalid padding (wrong key?)")
+	}
+	return out[:len(out)-n], nil
+}
+
+// decryptValue decrypts v, which must be encrypted, and checks its MAC.
+func (kr *keyring) decryptValue(v *xmlValue) ([]byte, error) {
+	if kr == nil {
+		return nil, errors.New("encrypted value without an encryption key")
+	}
+	if kr.macKey == nil {
+		return nil, errors.New("encrypted value without a MAC method")
+	}
+	if v.ValueMAC == "" {
+		return nil, errors.New("missing value MAC")
+	}
+	want, err := decodeBase64(v.ValueMAC)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value MAC: %w", err)
+	}
+	raw, err := decodeBase64(v.EncryptedValue.CipherData.CipherValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cipher value: %w", err)
+	}
+	h := hmac.New(kr.macAlg, kr.macKey)
+	h.Write(raw)
+	if !hmac.Equal(h.Sum(nil), want) {
+		return nil, errors.New("value MAC does not match")
+	}
+	return kr.decrypt(v.EncryptedValue)
+}
+
+// encrypt encrypts data with the encryption key.
+func (kr *keyring) encrypt(data []byte) *xmlEncrypted {
+	block, err := aes.NewCipher(kr.encKey)
+	if err != nil {
+		panic(err) // the key length is checked by the caller
+	}
+	n := aes.BlockSize - len(data)%aes.BlockSize
+	padded := append(bytes.Clone(data), bytes.Repeat([]byte{byte(n)}, n)...)
+
+	out := make([]byte, aes.BlockSize+len(padded))
+	rand.Read(out[:aes.BlockSize]) // IV
+	cipher.NewCBCEncrypter(block, out[:aes.BlockSize]).CryptBlocks(out[aes.BlockSize:], padded)
+	return &xmlEncrypted{
+		Method:     xmlMethod{Algorithm: aesAlgorithm(len(kr.encKey))},
+		CipherData: xmlCipherData{CipherValue: base64.StdEncoding.EncodeToString(out)},
+	}
+}
+
+// encryptValue encrypts data and computes its MAC.
+func (kr *keyring) encryptValue(data []byte) *xmlValue {
+	ev := kr.encrypt(data)
+	raw, _ := base64.StdEncoding.DecodeString(ev.CipherData.CipherValue)
+	h := hmac.New(kr.macAlg, kr.macKey)
+	h.Write(raw)
+	return &xmlValue{
+		EncryptedValue: ev,
+		ValueMAC:       base64.StdEncoding.EncodeToString(h.Sum(nil)),
+	}
+}
+
+func aesKeyLength(alg string) int {
+	switch alg {
+	case algAES128CBC:
+		return 16
+	case algAES192CBC:
+		return 24
+	case algAES256CBC:
+		return 32
+	}
+	return 0
+}
+
+func aesAlgorithm(keyLen int) string {
+	switch keyLen {
+	case 16:
+		return algAES128CBC
+	case 24:
+		return algAES192CBC
+	case 32:
+		return algAES256CBC
+	}
+	return ""
+}
+
+// decodeBase64 decodes s as standard base64, ignoring whitespace.
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
@@ -0,0 +1,420 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+// Package pskc reads and writes Portable Symmetric Key Container (PSKC)
+// documents, the XML format specified by RFC 6030 in which hardware token
+// vendors distribute token secrets.
+//
+// Secrets may be stored in plaintext, or encrypted with AES-CBC under a key
+// that is either pre-shared or derived from a password with PBKDF2. Encrypted
+// values are authenticated with an HMAC. Digital signatures and asymmetric
+// key transport are not supported.
+//
+// See https://tools.ietf.org/html/rfc6030
+package pskc
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/creachadair/otp"
+	"github.com/creachadair/otp/otpauth"
+)
+
+// Algorithm URIs for the key types supported by this package.
+const (
+	AlgorithmHOTP = "urn:ietf:params:xml:ns:keyprov:pskc:hotp"
+	AlgorithmTOTP = "urn:ietf:params:xml:ns:keyprov:pskc:totp"
+)
+
+// A Key is a single key from a PSKC container, combining the settings of a
+// KeyPackage element and the device information that accompanies it.
+type Key struct {
+	ID        string // the key identifier
+	Algorithm string // the algorithm URI, e.g., AlgorithmHOTP
+
+	Issuer       string
+	FriendlyName string
+	UserID       string
+
+	Manufacturer string // from DeviceInfo
+	SerialNo     string // from DeviceInfo
+	Model        string // from DeviceInfo
+
+	Suite    string // the algorithm suite, e.g., "HMAC-SHA256"; empty means SHA-1
+	Digits   int    // the response length; 0 if unspecified
+	Encoding string // the response encoding, e.g., "DECIMAL"
+
+	Secret       []byte
+	Counter      uint64
+	TimeInterval int // in seconds; 0 if unspecified
+}
+
+// Options carry the secrets used to decrypt or encrypt a container.
+// A nil *Options is ready for use, and supports only plaintext containers.
+type Options struct {
+	// Password is used for containers whose encryption key is derived with
+	// PBKDF2. When writing, if set, the secrets are encrypted with a key
+	// derived from Password.
+	Password string
+
+	// PreSharedKey is the AES key used for containers encrypted with a
+	// pre-shared key. It must be 16, 24, or 32 bytes long. When writing, if
+	// set, the secrets are encrypted with this key.
+	PreSharedKey []byte
+
+	// KeyName is the name of the pre-shared key or password recorded in a
+	// container when writing. If empty, a generic name is used.
+	KeyName string
+
+	// Iterations is the PBKDF2 iteration count used when writing with a
+	// password. If zero, the default is 100000. It must not exceed 10000000,
+	// the largest count accepted by Parse.
+	Iterations int
+}
+
+func (o *Options) password() string {
+	if o == nil {
+		return ""
+	}
+	return o.Password
+}
+
+func (o *Options) preSharedKey() []byte {
+	if o == nil {
+		return nil
+	}
+	return o.PreSharedKey
+}
+
+func (o *Options) keyName(def string) string {
+	if o == nil || o.KeyName == "" {
+		return def
+	}
+	return o.KeyName
+}
+
+func (o *Options) iterations() int {
+	if o == nil || o.Iterations <= 0 {
+		return 100000
+	}
+	return o.Iterations
+}
+
+// Parse parses data as a PSKC document and returns the keys it contains.
+// Encrypted values are decrypted using the secrets in opts, and it is an
+// error if the required secret is not provided or is incorrect.
+func Parse(data []byte, opts *Options) ([]*Key, error) {
+	var c xmlContainer
+	if err := xml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid PSKC document: %w", err)
+	}
+	if c.Version != "1.0" {
+		return nil, fmt.Errorf("unsupported PSKC version %q", c.Version)
+	}
+	kr, err := newKeyring(&c, opts)
+	if err != nil {
+		return nil, err
+	}
+	var out []*Key
+	for i, kp := range c.KeyPackages {
+		key, err := kr.parseKey(kp)
+		if err != nil {
+			return nil, fmt.Errorf("key package %d: %w", i+1, err)
+		}
+		out = append(out, key)
+	}
+	return out, nil
+}
+
+func (kr *keyring) parseKey(kp *xmlKeyPackage) (*Key, error) {
+	xk := kp.Key
+	if xk == nil {
+		return nil, errors.New("missing key")
+	}
+	out := &Key{
+		ID:           xk.ID,
+		Algorithm:    xk.Algorithm,
+		Issuer:       xk.Issuer,
+		FriendlyName: xk.FriendlyName,
+		UserID:       xk.UserID,
+	}
+	if di := kp.DeviceInfo; di != nil {
+		out.Manufacturer = di.Manufacturer
+		out.SerialNo = di.SerialNo
+		out.Model = di.Model
+	}
+	if ap := xk.AlgorithmParameters; ap != nil {
+		out.Suite = ap.Suite
+		if rf := ap.ResponseFormat; rf != nil {
+			out.Digits = rf.Length
+			out.Encoding = rf.Encoding
+		}
+	}
+	if xk.Data == nil {
+		return out, nil
+	}
+
+	var err error
+	if v := xk.Data.Secret; v != nil {
+		out.Secret, err = kr.bytesValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("secret: %w", err)
+		}
+	}
+	if v := xk.Data.Counter; v != nil {
+		out.Counter, err = kr.intValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("counter: %w", err)
+		}
+	}
+	if v := xk.Data.TimeInterval; v != nil {
+		n, err := kr.intValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("time interval: %w", err)
+		}
+		out.TimeInterval = int(n)
+	}
+	return out, nil
+}
+
+// bytesValue returns the contents of a binary value. A plain value is encoded
+// as base64.
+func (kr *keyring) bytesValue(v *xmlValue) ([]byte, error) {
+	if v.PlainValue != nil {
+		return decodeBase64(*v.PlainValue)
+	} else if v.EncryptedValue != nil {
+		return kr.decryptValue(v)
+	}
+	return nil, errors.New("missing value")
+}
+
+// intValue returns the contents of an integer value. A plain value is encoded
+// in decimal, and an encrypted value as a big-endian integer.
+func (kr *keyring) intValue(v *xmlValue) (uint64, error) {
+	if v.PlainValue != nil {
+		return strconv.ParseUint(strings.TrimSpace(*v.PlainValue), 10, 64)
+	} else if v.EncryptedValue != nil {
+		raw, err := kr.decryptValue(v)
+		if err != nil {
+			return 0, err
+		} else if len(raw) > 8 {
+			return 0, errors.New("integer value too long")
+		}
+		var buf [8]byte
+		copy(buf[8-len(raw):], raw)
+		return binary.BigEndian.Uint64(buf[:]), nil
+	}
+	return 0, errors.New("missing value")
+}
+
+// Marshal encodes keys as a PSKC document. If opts specifies a password or a
+// pre-shared key, the secrets are encrypted with AES-CBC and authenticated
+// with HMAC-SHA1; otherwise they are stored in plaintext. Keys with an empty
+// ID are assigned their index in keys, starting from 1.
+func Marshal(keys []*Key, opts *Options) ([]byte, error) {
+	c := &xmlContainer{Version: "1.0"}
+	kr, err := newWriteKeyring(c, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i, k := range keys {
+		c.KeyPackages = append(c.KeyPackages, kr.formatKey(k, i+1))
+	}
+	data, err := xml.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// newWriteKeyring constructs a keyring for encrypting values as specified by
+// opts, and records its settings in c. It returns nil if the values should
+// not be encrypted.
+func newWriteKeyring(c *xmlContainer, opts *Options) (*keyring, error) {
+	kr := new(keyring)
+	switch pw, psk := opts.password(), opts.preSharedKey(); {
+	case pw != "" && psk != nil:
+		return nil, errors.New("both password and pre-shared key are set")
+	case pw != "":
+		salt := make([]byte, 16)
+		rand.Read(salt)
+		params := &xmlPBKDF2Params{
+			Salt:           base64.StdEncoding.EncodeToString(salt),
+			IterationCount: opts.iterations(),
+			KeyLength:      16,
+		}
+		key, err := deriveKey(params, pw)
+		if err != nil {
+			return nil, err
+		}
+		kr.encKey = key
+		c.EncryptionKey = &xmlEncryptionKey{DerivedKey: &xmlDerivedKey{
+			Method:        xmlDerivationMethod{Algorithm: algPBKDF2, Params: params},
+			MasterKeyName: opts.keyName("Password"),
+		}}
+	case psk != nil:
+		if aesAlgorithm(len(psk)) == "" {
+			return nil, fmt.Errorf("pre-shared key has length %d, want 16, 24, or 32", len(psk))
+		}
+		kr.encKey = psk
+		c.EncryptionKey = &xmlEncryptionKey{KeyName: opts.keyName("Pre-shared-key")}
+	default:
+		return nil, nil
+	}
+
+	kr.macAlg = sha1.New
+	kr.macKey = make([]byte, 20)
+	rand.Read(kr.macKey)
+	c.MACMethod = &xmlMACMethod{Algorithm: algHMACSHA1, MACKey: kr.encrypt(kr.macKey)}
+	return kr, nil
+}
+
+func (kr *keyring) formatKey(k *Key, index int) *xmlKeyPackage {
+	xk := &xmlKey{
+		ID:           k.ID,
+		Algorithm:    k.Algorithm,
+		Issuer:       k.Issuer,
+		FriendlyName: k.FriendlyName,
+		UserID:       k.UserID,
+		Data:         new(xmlData),
+	}
+	if xk.ID == "" {
+		xk.ID = strconv.Itoa(index)
+	}
+	if k.Suite != "" || k.Digits > 0 {
+		xk.AlgorithmParameters = &xmlAlgParams{Suite: k.Suite}
+		if k.Digits > 0 {
+			enc := k.Encoding
+			if enc == "" {
+				enc = "DECIMAL"
+			}
+			xk.AlgorithmParameters.ResponseFormat = &xmlResponseFormat{Length: k.Digits, Encoding: enc}
+		}
+	}
+	if kr == nil {
+		xk.Data.Secret = plainValue(base64.StdEncoding.EncodeToString(k.Secret))
+	} else {
+		xk.Data.Secret = kr.encryptValue(k.Secret)
+	}
+	if k.Algorithm == AlgorithmHOTP || k.Counter != 0 {
+		xk.Data.Counter = plainValue(strconv.FormatUint(k.Counter, 10))
+	}
+	if k.TimeInterval > 0 {
+		xk.Data.TimeInterval = plainValue(strconv.Itoa(k.TimeInterval))
+	}
+
+	out := &xmlKeyPackage{Key: xk}
+	if k.Manufacturer != "" || k.SerialNo != "" || k.Model != "" {
+		out.DeviceInfo = &xmlDeviceInfo{
+			Manufacturer: k.Manufacturer,
+			SerialNo:     k.SerialNo,
+			Model:        k.Model,
+		}
+	}
+	return out
+}
+
+func plainValue(s string) *xmlValue { return &xmlValue{PlainValue: &s} }
+
+// URL returns an otpauth URL with the settings of k. The account name is the
+// first non-empty field among FriendlyName, UserID, SerialNo, and ID.
+// It reports an error if k is not an HOTP or TOTP key, if it uses a response
+// encoding other than decimal, or if its suite names an unknown hash.
+func (k *Key) URL() (*otpauth.URL, error) {
+	u := &otpauth.URL{
+		Issuer:    k.Issuer,
+		Algorithm: otp.SHA1.String(),
+		Digits:    k.Digits,
+		Period:    k.TimeInterval,
+		Counter:   k.Counter,
+	}
+	switch k.Algorithm {
+	case AlgorithmHOTP:
+		u.Type = "hotp"
+	case AlgorithmTOTP:
+		u.Type = "totp"
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+	}
+	if k.Encoding != "" && k.Encoding != "DECIMAL" {
+		return nil, fmt.Errorf("unsupported response encoding %q", k.Encoding)
+	}
+	if k.Suite != "" {
+		alg, err := otp.ParseAlgorithm(strings.TrimPrefix(strings.ToUpper(k.Suite), "HMAC-"))
+		if err != nil {
+			return nil, fmt.Errorf("unsupported suite %q", k.Suite)
+		}
+		u.Algorithm = alg.String()
+	}
+	if u.Digits <= 0 {
+		u.Digits = 6
+	}
+	if u.Period <= 0 {
+		u.Period = 30
+	}
+	for _, s := range []string{k.FriendlyName, k.UserID, k.SerialNo, k.ID} {
+		if s != "" {
+			u.Account = s
+			break
+		}
+	}
+	if u.Account == "" {
+		return nil, errors.New("key has no name")
+	}
+	u.SetSecret(k.Secret)
+	return u, nil
+}
+
+// Config returns an otp.Config with the settings of k.
+// It reports an error if k cannot be represented as a URL.
+func (k *Key) Config() (otp.Config, error) {
+	u, err := k.URL()
+	if err != nil {
+		return otp.Config{}, err
+	}
+	return u.Config()
+}
+
+// KeyFromURL returns a Key with the settings of u, which must be an HOTP or
+// TOTP URL. The account name of u is stored as the FriendlyName of the key.
+func KeyFromURL(u *otpauth.URL) (*Key, error) {
+	key, err := u.Secret()
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret: %w", err)
+	}
+	out := &Key{
+		Issuer:       u.Issuer,
+		FriendlyName: u.Account,
+		Digits:       u.Digits,
+		Secret:       key,
+		Counter:      u.Counter,
+	}
+	switch u.Type {
+	case "hotp":
+		out.Algorithm = AlgorithmHOTP
+	case "totp":
+		out.Algorithm = AlgorithmTOTP
+		out.TimeInterval = u.Period
+	default:
+		return nil, fmt.Errorf("unsupported type %q", u.Type)
+	}
+	if u.Encoder != "" {
+		return nil, fmt.Errorf("unsupported encoder %q", u.Encoder)
+	}
+	if u.Algorithm != "" {
+		alg, err := otp.ParseAlgorithm(u.Algorithm)
+		if err != nil {
+			return nil, err
+		} else if alg != otp.SHA1 {
+			out.Suite = "HMAC-" + alg.String()
+		}
+	}
+	return out, nil
+}
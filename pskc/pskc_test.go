@@ -0,0 +1,377 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package pskc_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/creachadair/otp/otpauth"
+	"github.com/creachadair/otp/pskc"
+	"github.com/google/go-cmp/cmp"
+)
+
+// RFC 6030 Figure 3: a container with a plaintext secret.
+const plainDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<KeyContainer Version="1.0"
+    Id="exampleID1"
+    xmlns="urn:ietf:params:xml:ns:keyprov:pskc">
+    <KeyPackage>
+        <DeviceInfo>
+            <Manufacturer>Manufacturer</Manufacturer>
+            <SerialNo>987654321</SerialNo>
+            <UserId>DC=example-bank,DC=net</UserId>
+        </DeviceInfo>
+        <CryptoModuleInfo>
+            <Id>CM_ID_001</Id>
+        </CryptoModuleInfo>
+        <Key Id="12345678"
+            Algorithm="urn:ietf:params:xml:ns:keyprov:pskc:hotp">
+            <Issuer>Issuer</Issuer>
+            <AlgorithmParameters>
+                <ResponseFormat Length="8" Encoding="DECIMAL"/>
+            </AlgorithmParameters>
+            <Data>
+                <Secret>
+                    <PlainValue>MTIzNDU2Nzg5MDEyMzQ1Njc4OTA=
+                    </PlainValue>
+                </Secret>
+                <Counter>
+                    <PlainValue>0</PlainValue>
+                </Counter>
+            </Data>
+            <UserId>UID=jsmith,DC=example-bank,DC=net</UserId>
+        </Key>
+    </KeyPackage>
+</KeyContainer>`
+
+// RFC 6030 Figure 5: a container encrypted with a pre-shared AES key.
+const preSharedDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<KeyContainer Version="1.0"
+    xmlns="urn:ietf:params:xml:ns:keyprov:pskc"
+    xmlns:ds="http://www.w3.org/2000/09/xmldsig#"
+    xmlns:xenc="http://www.w3.org/2001/04/xmlenc#">
+    <EncryptionKey>
+        <ds:KeyName>Pre-shared-key</ds:KeyName>
+    </EncryptionKey>
+    <MACMethod Algorithm="http://www.w3.org/2000/09/xmldsig#hmac-sha1">
+        <MACKey>
+            <xenc:EncryptionMethod
+             Algorithm="http://www.w3.org/2001/04/xmlenc#aes128-cbc"/>
+            <xenc:CipherData>
+                <xenc:CipherValue>
+ESIzRFVmd4iZABEiM0RVZgKn6WjLaTC1sbeBMSvIhRejN9vJa2BOlSaMrR7I5wSX
+                </xenc:CipherValue>
+            </xenc:CipherData>
+        </MACKey>
+    </MACMethod>
+    <KeyPackage>
+        <DeviceInfo>
+            <Manufacturer>Manufacturer</Manufacturer>
+            <SerialNo>987654321</SerialNo>
+        </DeviceInfo>
+        <CryptoModuleInfo>
+            <Id>CM_ID_001</Id>
+        </CryptoModuleInfo>
+        <Key Id="12345678"
+            Algorithm="urn:ietf:params:xml:ns:keyprov:pskc:hotp">
+            <Issuer>Issuer</Issuer>
+            <AlgorithmParameters>
+                <ResponseFormat Length="8" Encoding="DECIMAL"/>
+            </AlgorithmParameters>
+            <Data>
+                <Secret>
+                    <EncryptedValue>
+                        <xenc:EncryptionMethod
+             Algorithm="http://www.w3.org/2001/04/xmlenc#aes128-cbc"/>
+                        <xenc:CipherData>
+                            <xenc:CipherValue>
+AAECAwQFBgcICQoLDA0OD+cIHItlB3Wra1DUpxVvOx2lef1VmNPCMl8jwZqIUqGv
+                            </xenc:CipherValue>
+                        </xenc:CipherData>
+                    </EncryptedValue>
+                    <ValueMAC>Su+NvtQfmvfJzF6bmQiJqoLRExc=
+                    </ValueMAC>
+                </Secret>
+                <Counter>
+                    <PlainValue>0</PlainValue>
+                </Counter>
+            </Data>
+        </Key>
+    </KeyPackage>
+</KeyContainer>`
+
+// RFC 6030 Figure 6: a container encrypted with a PBKDF2-derived key.
+const derivedDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<pskc:KeyContainer
+  xmlns:pskc="urn:ietf:params:xml:ns:keyprov:pskc"
+  xmlns:xenc11="http://www.w3.org/2009/xmlenc11#"
+  xmlns:pkcs5=
+  "http://www.rsasecurity.com/rsalabs/pkcs/schemas/pkcs-5v2-0#"
+  xmlns:xenc="http://www.w3.org/2001/04/xmlenc#" Version="1.0">
+    <pskc:EncryptionKey>
+        <xenc11:DerivedKey>
+            <xenc11:KeyDerivationMethod
+              Algorithm=
+"http://www.rsasecurity.com/rsalabs/pkcs/schemas/pkcs-5v2-0#pbkdf2">
+                <pkcs5:PBKDF2-params>
+                    <Salt>
+                        <Specified>Ej7/PEpyEpw=</Specified>
+                    </Salt>
+                    <IterationCount>1000</IterationCount>
+                    <KeyLength>16</KeyLength>
+                    <PRF/>
+                </pkcs5:PBKDF2-params>
+            </xenc11:KeyDerivationMethod>
+            <xenc:ReferenceList>
+                <xenc:DataReference URI="#ED"/>
+            </xenc:ReferenceList>
+            <xenc11:MasterKeyName>My Password 1</xenc11:MasterKeyName>
+        </xenc11:DerivedKey>
+    </pskc:EncryptionKey>
+    <pskc:MACMethod
+        Algorithm="http://www.w3.org/2000/09/xmldsig#hmac-sha1">
+        <pskc:MACKey>
+            <xenc:EncryptionMethod
+            Algorithm="http://www.w3.org/2001/04/xmlenc#aes128-cbc"/>
+            <xenc:CipherData>
+                <xenc:CipherValue>
+2GTTnLwM3I4e5IO5FkufoOEiOhNj91fhKRQBtBJYluUDsPOLTfUvoU2dStyOwYZx
+                </xenc:CipherValue>
+            </xenc:CipherData>
+        </pskc:MACKey>
+    </pskc:MACMethod>
+    <pskc:KeyPackage>
+        <pskc:DeviceInfo>
+            <pskc:Manufacturer>TokenVendorAcme</pskc:Manufacturer>
+            <pskc:SerialNo>987654321</pskc:SerialNo>
+        </pskc:DeviceInfo>
+        <pskc:CryptoModuleInfo>
+            <pskc:Id>CM_ID_001</pskc:Id>
+        </pskc:CryptoModuleInfo>
+        <pskc:Key Algorithm=
+        "urn:ietf:params:xml:ns:keyprov:pskc:hotp" Id="123456">
+            <pskc:Issuer>Example-Issuer</pskc:Issuer>
+            <pskc:AlgorithmParameters>
+                <pskc:ResponseFormat Length="8"
+                 Encoding="DECIMAL"/>
+            </pskc:AlgorithmParameters>
+            <pskc:Data>
+                <pskc:Secret>
+                <pskc:EncryptedValue Id="ED">
+                    <xenc:EncryptionMethod
+                        Algorithm=
+"http://www.w3.org/2001/04/xmlenc#aes128-cbc"/>
+                        <xenc:CipherData>
+                            <xenc:CipherValue>
+      oTvo+S22nsmS2Z/RtcoF8Hfh+jzMe0RkiafpoDpnoZTjPYZu6V+A4aEn032yCr4f
+                        </xenc:CipherValue>
+                    </xenc:CipherData>
+                    </pskc:EncryptedValue>
+                    <pskc:ValueMAC>LP6xMvjtypbfT9PdkJhBZ+D6O4w=
+                    </pskc:ValueMAC>
+                </pskc:Secret>
+                <pskc:Counter>
+                  <pskc:PlainValue>0</pskc:PlainValue>
+                </pskc:Counter>
+            </pskc:Data>
+        </pskc:Key>
+    </pskc:KeyPackage>
+</pskc:KeyContainer>`
+
+var rfcSecret = []byte("12345678901234567890")
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestParseRFC(t *testing.T) {
+	psk := mustHex("12345678901234567890123456789012")
+	tests := []struct {
+		name  string
+		input string
+		opts  *pskc.Options
+		want  *pskc.Key
+	}{
+		{"Plain", plainDoc, nil, &pskc.Key{
+			ID: "12345678", Algorithm: pskc.AlgorithmHOTP, Issuer: "Issuer",
+			UserID:       "UID=jsmith,DC=example-bank,DC=net",
+			Manufacturer: "Manufacturer", SerialNo: "987654321",
+			Digits: 8, Encoding: "DECIMAL", Secret: rfcSecret,
+		}},
+		{"PreShared", preSharedDoc, &pskc.Options{PreSharedKey: psk}, &pskc.Key{
+			ID: "12345678", Algorithm: pskc.AlgorithmHOTP, Issuer: "Issuer",
+			Manufacturer: "Manufacturer", SerialNo: "987654321",
+			Digits: 8, Encoding: "DECIMAL", Secret: rfcSecret,
+		}},
+		{"PBKDF2", derivedDoc, &pskc.Options{Password: "qwerty"}, &pskc.Key{
+			ID: "123456", Algorithm: pskc.AlgorithmHOTP, Issuer: "Example-Issuer",
+			Manufacturer: "TokenVendorAcme", SerialNo: "987654321",
+			Digits: 8, Encoding: "DECIMAL", Secret: rfcSecret,
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, err := pskc.Parse([]byte(tc.input), tc.opts)
+			if err != nil {
+				t.Fatalf("Parse: unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(keys, []*pskc.Key{tc.want}); diff != "" {
+				t.Errorf("Parse (-got, +want):\n%s", diff)
+			}
+
+			// The RFC 4226 test key should produce the RFC 4226 test codes,
+			// extended to 8 digits.
+			cfg, err := keys[0].Config()
+			if err != nil {
+				t.Fatalf("Config: unexpected error: %v", err)
+			}
+			if got, want := cfg.HOTP(0), "84755224"; got != want {
+				t.Errorf("HOTP(0): got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	psk := mustHex("12345678901234567890123456789012")
+	tests := []struct {
+		name, input string
+		opts        *pskc.Options
+		want        string
+	}{
+		{"NotXML", "nonsense", nil, "invalid PSKC document"},
+		{"BadVersion", `<KeyContainer xmlns="urn:ietf:params:xml:ns:keyprov:pskc" Version="2.0"/>`,
+			nil, "unsupported PSKC version"},
+		{"NoPassword", derivedDoc, nil, "encrypted with a password"},
+		{"NoKey", preSharedDoc, nil, "pre-shared key"},
+		{"WrongPassword", derivedDoc, &pskc.Options{Password: "azerty"}, "MAC"},
+		{"WrongKeyLength", preSharedDoc, &pskc.Options{PreSharedKey: psk[:8]}, "key has length"},
+		{"HugeIterations", strings.Replace(derivedDoc, "<IterationCount>1000<", "<IterationCount>2147483647<", 1),
+			&pskc.Options{Password: "qwerty"}, "iteration count"},
+		{"HugeKeyLength", strings.Replace(derivedDoc, "<KeyLength>16<", "<KeyLength>4000000000<", 1),
+			&pskc.Options{Password: "qwerty"}, "key length"},
+		{"OddKeyLength", strings.Replace(derivedDoc, "<KeyLength>16<", "<KeyLength>20<", 1),
+			&pskc.Options{Password: "qwerty"}, "key length"},
+		{"BadValueMAC", strings.Replace(preSharedDoc, "Su+NvtQf", "Su+NvtQg", 1),
+			&pskc.Options{PreSharedKey: psk}, "value MAC does not match"},
+		{"MissingValueMAC", strings.Replace(preSharedDoc, "<ValueMAC>Su+NvtQfmvfJzF6bmQiJqoLRExc=\n                    </ValueMAC>", "", 1),
+			&pskc.Options{PreSharedKey: psk}, "missing value MAC"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, err := pskc.Parse([]byte(tc.input), tc.opts)
+			if err == nil {
+				t.Fatalf("Parse: got %+v, want error", keys)
+			} else if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Parse: got error %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	keys := []*pskc.Key{{
+		Algorithm: pskc.AlgorithmHOTP, Issuer: "Example", FriendlyName: "alice",
+		SerialNo: "12345", Digits: 6, Encoding: "DECIMAL",
+		Secret: rfcSecret, Counter: 1024,
+	}, {
+		ID: "bob-totp", Algorithm: pskc.AlgorithmTOTP, Issuer: "Example", UserID: "bob",
+		Suite: "HMAC-SHA256", Digits: 8, Encoding: "DECIMAL",
+		Secret: []byte("12345678901234567890123456789012"), TimeInterval: 60,
+	}}
+	tests := []struct {
+		name string
+		opts *pskc.Options
+		want string // a string that must appear in the output
+	}{
+		{"Plain", nil, "<PlainValue>MTIzNDU2Nzg5MDEyMzQ1Njc4OTA=</PlainValue>"},
+		{"Password", &pskc.Options{Password: "hunter2", Iterations: 1000}, "<IterationCount>1000</IterationCount>"},
+		{"PreShared128", &pskc.Options{PreSharedKey: bytes.Repeat([]byte{1}, 16)}, "aes128-cbc"},
+		{"PreShared256", &pskc.Options{PreSharedKey: bytes.Repeat([]byte{2}, 32), KeyName: "vendor"}, "vendor"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := pskc.Marshal(keys, tc.opts)
+			if err != nil {
+				t.Fatalf("Marshal: unexpected error: %v", err)
+			}
+			if !bytes.Contains(data, []byte(tc.want)) {
+				t.Errorf("Marshal output does not contain %q:\n%s", tc.want, data)
+			}
+			if tc.opts != nil && bytes.Contains(data, []byte("PlainValue>MTIz")) {
+				t.Errorf("Marshal output contains a plaintext secret:\n%s", data)
+			}
+
+			got, err := pskc.Parse(data, tc.opts)
+			if err != nil {
+				t.Fatalf("Parse: unexpected error: %v", err)
+			}
+			want := []*pskc.Key{new(pskc.Key), new(pskc.Key)}
+			*want[0], *want[1] = *keys[0], *keys[1]
+			want[0].ID = "1" // assigned by Marshal
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Errorf("Parse (-got, +want):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("Conflict", func(t *testing.T) {
+		_, err := pskc.Marshal(keys, &pskc.Options{Password: "x", PreSharedKey: make([]byte, 16)})
+		if err == nil {
+			t.Error("Marshal with password and key: got nil, want error")
+		}
+		// An iteration count that Parse would refuse is also refused here.
+		if _, err := pskc.Marshal(keys, &pskc.Options{Password: "x", Iterations: 1 << 30}); err == nil {
+			t.Error("Marshal with excessive iterations: got nil, want error")
+		}
+	})
+}
+
+func TestURL(t *testing.T) {
+	k := &pskc.Key{
+		ID: "123", Algorithm: pskc.AlgorithmTOTP, Issuer: "Example", UserID: "bob",
+		Suite: "HMAC-SHA256", Digits: 8, Encoding: "DECIMAL",
+		Secret: rfcSecret, TimeInterval: 60,
+	}
+	u, err := k.URL()
+	if err != nil {
+		t.Fatalf("URL: unexpected error: %v", err)
+	}
+	want := &otpauth.URL{
+		Type: "totp", Issuer: "Example", Account: "bob",
+		RawSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		Algorithm: "SHA256", Digits: 8, Period: 60,
+	}
+	if diff := cmp.Diff(u, want); diff != "" {
+		t.Errorf("URL (-got, +want):\n%s", diff)
+	}
+
+	back, err := pskc.KeyFromURL(u)
+	if err != nil {
+		t.Fatalf("KeyFromURL: unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(back, &pskc.Key{
+		Algorithm: pskc.AlgorithmTOTP, Issuer: "Example", FriendlyName: "bob",
+		Suite: "HMAC-SHA256", Digits: 8, Secret: rfcSecret, TimeInterval: 60,
+	}); diff != "" {
+		t.Errorf("KeyFromURL (-got, +want):\n%s", diff)
+	}
+
+	for _, bad := range []*pskc.Key{
+		{Algorithm: "urn:example:unknown", ID: "x"},
+		{Algorithm: pskc.AlgorithmHOTP, ID: "x", Encoding: "HEXADECIMAL"},
+		{Algorithm: pskc.AlgorithmHOTP, ID: "x", Suite: "HMAC-WHIRLPOOL"},
+		{Algorithm: pskc.AlgorithmHOTP},
+	} {
+		if u, err := bad.URL(); err == nil {
+			t.Errorf("URL %+v: got %v, want error", bad, u)
+		}
+	}
+}
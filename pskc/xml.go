@@ -0,0 +1,122 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package pskc
+
+import "encoding/xml"
+
+// XML namespaces used by PSKC documents.
+const (
+	nsPSKC   = "urn:ietf:params:xml:ns:keyprov:pskc"
+	nsDSig   = "http://www.w3.org/2000/09/xmldsig#"
+	nsXEnc   = "http://www.w3.org/2001/04/xmlenc#"
+	nsXEnc11 = "http://www.w3.org/2009/xmlenc11#"
+	nsPKCS5  = "http://www.rsasecurity.com/rsalabs/pkcs/schemas/pkcs-5v2-0#"
+)
+
+// Algorithm identifiers for encryption, key derivation, and MAC.
+const (
+	algAES128CBC  = nsXEnc + "aes128-cbc"
+	algAES192CBC  = nsXEnc + "aes192-cbc"
+	algAES256CBC  = nsXEnc + "aes256-cbc"
+	algPBKDF2     = nsPKCS5 + "pbkdf2"
+	algHMACSHA1   = nsDSig + "hmac-sha1"
+	algHMACSHA256 = "http://www.w3.org/2001/04/xmldsig-more#hmac-sha256"
+)
+
+// The types in this file describe the subset of the PSKC schema (RFC 6030
+// Section 11) supported by this package. Elements in the PSKC namespace are
+// matched by local name only, so documents that use a namespace prefix for
+// PSKC elements are accepted.
+
+type xmlContainer struct {
+	XMLName       xml.Name          `xml:"urn:ietf:params:xml:ns:keyprov:pskc KeyContainer"`
+	Version       string            `xml:"Version,attr"`
+	ID            string            `xml:"Id,attr,omitempty"`
+	EncryptionKey *xmlEncryptionKey `xml:"EncryptionKey"`
+	MACMethod     *xmlMACMethod     `xml:"MACMethod"`
+	KeyPackages   []*xmlKeyPackage  `xml:"KeyPackage"`
+}
+
+type xmlEncryptionKey struct {
+	KeyName    string         `xml:"http://www.w3.org/2000/09/xmldsig# KeyName,omitempty"`
+	DerivedKey *xmlDerivedKey `xml:"http://www.w3.org/2009/xmlenc11# DerivedKey"`
+}
+
+type xmlDerivedKey struct {
+	Method        xmlDerivationMethod `xml:"http://www.w3.org/2009/xmlenc11# KeyDerivationMethod"`
+	MasterKeyName string              `xml:"http://www.w3.org/2009/xmlenc11# MasterKeyName,omitempty"`
+}
+
+type xmlDerivationMethod struct {
+	Algorithm string           `xml:"Algorithm,attr"`
+	Params    *xmlPBKDF2Params `xml:"http://www.rsasecurity.com/rsalabs/pkcs/schemas/pkcs-5v2-0# PBKDF2-params"`
+}
+
+type xmlPBKDF2Params struct {
+	Salt           string     `xml:"Salt>Specified"` // base64
+	IterationCount int        `xml:"IterationCount"`
+	KeyLength      int        `xml:"KeyLength"`
+	PRF            *xmlMethod `xml:"PRF"`
+}
+
+type xmlMACMethod struct {
+	Algorithm string        `xml:"Algorithm,attr"`
+	MACKey    *xmlEncrypted `xml:"MACKey"`
+}
+
+type xmlMethod struct {
+	Algorithm string `xml:"Algorithm,attr,omitempty"`
+}
+
+type xmlEncrypted struct {
+	ID         string        `xml:"Id,attr,omitempty"`
+	Method     xmlMethod     `xml:"http://www.w3.org/2001/04/xmlenc# EncryptionMethod"`
+	CipherData xmlCipherData `xml:"http://www.w3.org/2001/04/xmlenc# CipherData"`
+}
+
+type xmlCipherData struct {
+	CipherValue string `xml:"http://www.w3.org/2001/04/xmlenc# CipherValue"` // base64
+}
+
+type xmlKeyPackage struct {
+	DeviceInfo *xmlDeviceInfo `xml:"DeviceInfo"`
+	Key        *xmlKey        `xml:"Key"`
+}
+
+type xmlDeviceInfo struct {
+	Manufacturer string `xml:"Manufacturer,omitempty"`
+	SerialNo     string `xml:"SerialNo,omitempty"`
+	Model        string `xml:"Model,omitempty"`
+}
+
+type xmlKey struct {
+	ID                  string        `xml:"Id,attr"`
+	Algorithm           string        `xml:"Algorithm,attr,omitempty"`
+	Issuer              string        `xml:"Issuer,omitempty"`
+	AlgorithmParameters *xmlAlgParams `xml:"AlgorithmParameters"`
+	FriendlyName        string        `xml:"FriendlyName,omitempty"`
+	Data                *xmlData      `xml:"Data"`
+	UserID              string        `xml:"UserId,omitempty"`
+}
+
+type xmlAlgParams struct {
+	Suite          string             `xml:"Suite,omitempty"`
+	ResponseFormat *xmlResponseFormat `xml:"ResponseFormat"`
+}
+
+type xmlResponseFormat struct {
+	Length   int    `xml:"Length,attr"`
+	Encoding string `xml:"Encoding,attr"`
+}
+
+type xmlData struct {
+	Secret       *xmlValue `xml:"Secret"`
+	Counter      *xmlValue `xml:"Counter"`
+	TimeInterval *xmlValue `xml:"TimeInterval"`
+}
+
+type xmlValue struct {
+	PlainValue     *string       `xml:"PlainValue"`
+	EncryptedValue *xmlEncrypted `xml:"EncryptedValue"`
+	ValueMAC       string        `xml:"ValueMAC,omitempty"` // base64
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrReplayed is reported when a code is presented for a time step or counter
+// value that is not later than the last one accepted for the same subject.
+var ErrReplayed = errors.New("code has already been used")
+
+// A UsedStepStore records the last time step (for TOTP) or counter value (for
+// HOTP) accepted for each subject, so that a verifier can reject replayed
+// codes as required by RFC 6238 Section 5.2. A subject is an arbitrary string
+// chosen by the caller to identify a single key, such as a user ID.
+//
+// Implementations must be safe for concurrent use by multiple goroutines.
+type UsedStepStore interface {
+	// UseStep records that step was accepted for subject. If step is not
+	// strictly greater than the last step recorded for subject, UseStep must
+	// report ErrReplayed and leave the record unchanged.
+	UseStep(subject string, step uint64) error
+
+	// LastStep reports the last step recorded for subject, and whether any
+	// step has been recorded for subject.
+	LastStep(subject string) (uint64, bool, error)
+}
+
+// MemStepStore is an in-memory implementation of the UsedStepStore interface.
+// A zero value is ready for use.
+type MemStepStore struct {
+	μ    sync.Mutex
+	last map[string]uint64
+}
+
+// UseStep implements a method of the UsedStepStore interface.
+func (m *MemStepStore) UseStep(subject string, step uint64) error {
+	m.μ.Lock()
+	defer m.μ.Unlock()
+	if last, ok := m.last[subject]; ok && step <= last {
+		return ErrReplayed
+	}
+	if m.last == nil {
+		m.last = make(map[string]uint64)
+	}
+	m.last[subject] = step
+	return nil
+}
+
+// LastStep implements a method of the UsedStepStore interface.
+func (m *MemStepStore) LastStep(subject string) (uint64, bool, error) {
+	m.μ.Lock()
+	defer m.μ.Unlock()
+	last, ok := m.last[subject]
+	return last, ok, nil
+}
+
+// FileStepStore is an implementation of the UsedStepStore interface that
+// persists its records as JSON in a file. Each update replaces the file
+// atomically. A FileStepStore is safe for concurrent use within a process,
+// but multiple processes must not share the same file.
+type FileStepStore struct {
+	path string
+
+	μ sync.Mutex
+}
+
+// NewFileStepStore constructs a FileStepStore that persists its records in the
+// file at path. The file need not exist; it is created on first use.
+func NewFileStepStore(path string) *FileStepStore { return &FileStepStore{path: path} }
+
+// UseStep implements a method of the UsedStepStore interface.
+func (f *FileStepStore) UseStep(subject string, step uint64) error {
+	f.μ.Lock()
+	defer f.μ.Unlock()
+
+	last, err := f.load()
+	if err != nil {
+		return err
+	}
+	if v, ok := last[subject]; ok && step <= v {
+		return ErrReplayed
+	}
+	last[subject] = step
+	return f.store(last)
+}
+
+// LastStep implements a method of the UsedStepStore interface.
+func (f *FileStepStore) LastStep(subject string) (uint64, bool, error) {
+	f.μ.Lock()
+	defer f.μ.Unlock()
+
+	last, err := f.load()
+	if err != nil {
+		return 0, false, err
+	}
+	v, ok := last[subject]
+	return v, ok, nil
+}
+
+func (f *FileStepStore) load() (map[string]uint64, error) {
+	last := make(map[string]uint64)
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return last, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &last); err != nil {
+		return nil, fmt.Errorf("invalid step store: %w", err)
+	}
+	return last, nil
+}
+
+func (f *FileStepStore) store(last map[string]uint64) error {
+	data, err := json.Marshal(last)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // in case of failure; harmless after rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
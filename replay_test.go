@@ -0,0 +1,137 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/creachadair/otp"
+)
+
+func testStepStore(t *testing.T, s otp.UsedStepStore) {
+	t.Helper()
+
+	steps := []struct {
+		subject string
+		step    uint64
+		want    error
+	}{
+		{"alice", 5, nil},
+		{"alice", 5, otp.ErrReplayed},
+		{"alice", 4, otp.ErrReplayed},
+		{"bob", 4, nil},
+		{"alice", 6, nil},
+		{"bob", 4, otp.ErrReplayed},
+		{"alice", 100, nil},
+		{"alice", 99, otp.ErrReplayed},
+		{"bob", 0, otp.ErrReplayed},
+		{"carol", 0, nil},
+		{"carol", 0, otp.ErrReplayed},
+	}
+	for _, tc := range steps {
+		if err := s.UseStep(tc.subject, tc.step); !errors.Is(err, tc.want) {
+			t.Errorf("UseStep(%q, %d): got %v, want %v", tc.subject, tc.step, err, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		subject string
+		want    uint64
+		ok      bool
+	}{
+		{"alice", 100, true},
+		{"bob", 4, true},
+		{"carol", 0, true},
+		{"dave", 0, false},
+	} {
+		got, ok, err := s.LastStep(tc.subject)
+		if err != nil || got != tc.want || ok != tc.ok {
+			t.Errorf("LastStep(%q): got (%d, %v, %v), want (%d, %v, nil)", tc.subject, got, ok, err, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestMemStepStore(t *testing.T) { testStepStore(t, new(otp.MemStepStore)) }
+
+func TestFileStepStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "steps.json")
+	testStepStore(t, otp.NewFileStepStore(path))
+
+	// A new store on the same file should see the same records.
+	s := otp.NewFileStepStore(path)
+	if err := s.UseStep("alice", 100); !errors.Is(err, otp.ErrReplayed) {
+		t.Errorf("UseStep after reopen: got %v, want %v", err, otp.ErrReplayed)
+	}
+	if err := s.UseStep("alice", 101); err != nil {
+		t.Errorf("UseStep after reopen: unexpected error: %v", err)
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	var now uint64 = 1000
+	v := otp.Verifier{
+		Config: otp.Config{
+			Key:      "12345678901234567890",
+			TimeStep: func() uint64 { return now },
+		},
+		Subject: "alice",
+		Options: &otp.VerifyOptions{Before: 1, After: 1, LookAhead: 5},
+		Store:   new(otp.MemStepStore),
+	}
+
+	t.Run("TOTP", func(t *testing.T) {
+		mustVerify := func(code string, wantOff int, wantErr error) {
+			t.Helper()
+			off, err := v.VerifyTOTP(code)
+			if !errors.Is(err, wantErr) {
+				t.Errorf("VerifyTOTP(%q): got error %v, want %v", code, err, wantErr)
+			} else if err == nil && off != wantOff {
+				t.Errorf("VerifyTOTP(%q): got offset %d, want %d", code, off, wantOff)
+			}
+		}
+		mustVerify("bogus", 0, otp.ErrInvalidCode)
+		mustVerify(v.Config.HOTP(999), -1, nil)
+		mustVerify(v.Config.HOTP(999), 0, otp.ErrReplayed) // same step
+		mustVerify(v.Config.HOTP(1000), 0, nil)
+		mustVerify(v.Config.HOTP(1000), 0, otp.ErrReplayed) // same step
+
+		// After the clock advances, an earlier step within the window is still
+		// rejected because a later one was already used.
+		now++
+		mustVerify(v.Config.HOTP(1000), 0, otp.ErrReplayed)
+		mustVerify(v.Config.HOTP(1001), 0, nil)
+	})
+
+	t.Run("HOTP", func(t *testing.T) {
+		v := v
+		v.Store = new(otp.MemStepStore)
+
+		if _, err := v.VerifyHOTP("bogus"); !errors.Is(err, otp.ErrInvalidCode) {
+			t.Errorf("VerifyHOTP: got %v, want %v", err, otp.ErrInvalidCode)
+		}
+		code := v.Config.HOTP(3)
+		if ctr, err := v.VerifyHOTP(code); err != nil || ctr != 3 {
+			t.Errorf("VerifyHOTP(%q): got (%d, %v), want (3, nil)", code, ctr, err)
+		}
+		if _, err := v.VerifyHOTP(code); !errors.Is(err, otp.ErrReplayed) {
+			t.Errorf("VerifyHOTP(%q) again: got %v, want %v", code, err, otp.ErrReplayed)
+		}
+		if _, err := v.VerifyHOTP(v.Config.HOTP(2)); !errors.Is(err, otp.ErrInvalidCode) {
+			t.Errorf("VerifyHOTP(earlier): got %v, want %v", err, otp.ErrInvalidCode)
+		}
+
+		// The window follows the last counter accepted, so a long run of
+		// consecutive codes is accepted without updating v.Config.
+		for want := uint64(4); want <= 20; want++ {
+			code := v.Config.HOTP(want)
+			if ctr, err := v.VerifyHOTP(code); err != nil || ctr != want {
+				t.Fatalf("VerifyHOTP(%q): got (%d, %v), want (%d, nil)", code, ctr, err, want)
+			}
+		}
+		if v.Config.Counter != 0 {
+			t.Errorf("Config.Counter: got %d, want 0", v.Config.Counter)
+		}
+	})
+}
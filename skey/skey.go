@@ -0,0 +1,265 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+// Package skey implements the hash-chain one-time password system described
+// in RFC 2289, a standardized form of S/KEY.
+//
+// A sequence of one-time passwords is derived from a secret passphrase and a
+// seed by applying a hash function repeatedly. The password with sequence
+// number n is the result of n+1 applications, so passwords are used in order
+// of decreasing sequence number, and the server need only store the last
+// password accepted: the next password is correct if its hash equals that
+// value.
+//
+// Passwords are 64-bit values, rendered either as hexadecimal or as six words
+// from a standard dictionary. The MD5 and SHA-1 algorithms are supported.
+//
+// See https://tools.ietf.org/html/rfc2289
+package skey
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/creachadair/otp"
+)
+
+var (
+	// ErrInvalid is reported when a response does not match the expected
+	// one-time password.
+	ErrInvalid = errors.New("invalid one-time password")
+
+	// ErrExhausted is reported when the sequence has no passwords remaining.
+	ErrExhausted = errors.New("one-time password sequence is exhausted")
+)
+
+// MinPassphraseLength is the minimum length of a secret passphrase.
+const MinPassphraseLength = 10
+
+// MaxSeedLength is the maximum length of a seed.
+const MaxSeedLength = 16
+
+// A Value is a 64-bit one-time password.
+type Value [8]byte
+
+// Compute returns the one-time password with sequence number seq for the
+// given passphrase and seed, using alg, which must be otp.MD5 or otp.SHA1.
+// The seed is not case-sensitive.
+func Compute(alg otp.Algorithm, passphrase, seed string, seq int) (Value, error) {
+	vs, err := Sequence(alg, passphrase, seed, seq, 1)
+	if err != nil {
+		return Value{}, err
+	}
+	return vs[0], nil
+}
+
+// Sequence returns n one-time passwords for the given passphrase and seed,
+// starting from sequence number seq and continuing in order of use, i.e., the
+// passwords for seq, seq-1, ..., seq-n+1. This is the form of a printed list.
+// It is an error if n > seq+1.
+func Sequence(alg otp.Algorithm, passphrase, seed string, seq, n int) ([]Value, error) {
+	if err := checkAlgorithm(alg); err != nil {
+		return nil, err
+	} else if len(passphrase) < MinPassphraseLength {
+		return nil, fmt.Errorf("passphrase must have at least %d characters", MinPassphraseLength)
+	} else if err := checkSeed(seed); err != nil {
+		return nil, err
+	} else if seq < 0 || n < 0 || n > seq+1 {
+		return nil, fmt.Errorf("invalid sequence range %d, %d", seq, n)
+	}
+
+	v := fold(alg, []byte(strings.ToLower(seed)+passphrase))
+	out := make([]Value, n)
+	for i := range seq + 1 {
+		if i > 0 {
+			v = fold(alg, v[:])
+		}
+		if j := seq - i; j < n {
+			out[j] = v
+		}
+	}
+	return out, nil
+}
+
+// Hex returns the hexadecimal representation of v, in uppercase.
+func (v Value) Hex() string { return strings.ToUpper(hex.EncodeToString(v[:])) }
+
+// String returns the hexadecimal representation of v.
+func (v Value) String() string { return v.Hex() }
+
+// Words returns the six-word representation of v, separated by spaces.
+func (v Value) Words() string {
+	bits := binary.BigEndian.Uint64(v[:])
+
+	// The last word includes a 2-bit checksum, the sum of the 2-bit pairs of
+	// the value, modulo 4.
+	var sum uint64
+	for i := 0; i < 64; i += 2 {
+		sum += (bits >> i) & 3
+	}
+	words := make([]string, 6)
+	for i := range words {
+		var idx uint64
+		if i < 5 {
+			idx = (bits >> (64 - 11*(i+1))) & 0x7ff
+		} else {
+			idx = (bits&0x1ff)<<2 | sum&3
+		}
+		words[i] = dictionary[idx]
+	}
+	return strings.Join(words, " ")
+}
+
+// ParseValue parses s as a one-time password in either hexadecimal or
+// six-word format. Case and whitespace are not significant. In the six-word
+// format, the digits 0, 1, and 5 are accepted in place of the letters O, L,
+// and S, as recommended by RFC 2289.
+func ParseValue(s string) (Value, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 6 {
+		return parseWords(fields)
+	}
+	var v Value
+	raw, err := hex.DecodeString(strings.Join(fields, ""))
+	if err != nil || len(raw) != len(v) {
+		return Value{}, errors.New("invalid one-time password format")
+	}
+	copy(v[:], raw)
+	return v, nil
+}
+
+var wordIndex = sync.OnceValue(func() map[string]uint64 {
+	m := make(map[string]uint64, len(dictionary))
+	for i, w := range dictionary {
+		m[w] = uint64(i)
+	}
+	return m
+})
+
+var wordFix = strings.NewReplacer("0", "O", "1", "L", "5", "S")
+
+func parseWords(words []string) (Value, error) {
+	var bits, sum uint64
+	for i, w := range words {
+		idx, ok := wordIndex()[wordFix.Replace(strings.ToUpper(w))]
+		if !ok {
+			return Value{}, fmt.Errorf("unknown word %q", w)
+		}
+		if i < 5 {
+			bits = bits<<11 | idx
+		} else {
+			bits = bits<<9 | idx>>2
+			sum = idx & 3
+		}
+	}
+	var v Value
+	binary.BigEndian.PutUint64(v[:], bits)
+
+	// Verify the checksum by re-encoding.
+	var check uint64
+	for i := 0; i < 64; i += 2 {
+		check += (bits >> i) & 3
+	}
+	if check&3 != sum {
+		return Value{}, errors.New("invalid one-time password checksum")
+	}
+	return v, nil
+}
+
+// A State is the record kept by a server to verify one-time passwords for a
+// single user. The next password expected has sequence number Seq-1.
+type State struct {
+	Algorithm otp.Algorithm // otp.MD5 or otp.SHA1
+	Seed      string        // the seed, in lowercase
+	Seq       int           // the sequence number of Last
+	Last      Value         // the last password accepted (or the initial value)
+}
+
+// NewState constructs a State for a new sequence of one-time passwords with
+// the given passphrase and seed. The state stores the password with sequence
+// number seq, which is never itself used; the first password expected has
+// sequence number seq-1.
+func NewState(alg otp.Algorithm, passphrase, seed string, seq int) (*State, error) {
+	v, err := Compute(alg, passphrase, seed, seq)
+	if err != nil {
+		return nil, err
+	}
+	return &State{Algorithm: alg, Seed: strings.ToLower(seed), Seq: seq, Last: v}, nil
+}
+
+// Challenge returns the challenge a server presents to request the next
+// password, e.g., "otp-md5 98 ke1234".
+func (s *State) Challenge() string {
+	return "otp-" + strings.ToLower(s.Algorithm.String()) +
+		" " + strconv.Itoa(s.Seq-1) + " " + s.Seed
+}
+
+// Verify checks response, in either format accepted by ParseValue, as the
+// next password in the sequence. On success it updates s to record the
+// response as the last password accepted. If the response is not correct, it
+// reports ErrInvalid; if the sequence has no passwords remaining, it reports
+// ErrExhausted. In either case s is not modified.
+func (s *State) Verify(response string) error {
+	if err := checkAlgorithm(s.Algorithm); err != nil {
+		return err
+	} else if s.Seq <= 0 {
+		return ErrExhausted
+	}
+	v, err := ParseValue(response)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if fold(s.Algorithm, v[:]) != s.Last {
+		return ErrInvalid
+	}
+	s.Last = v
+	s.Seq--
+	return nil
+}
+
+func checkAlgorithm(alg otp.Algorithm) error {
+	if alg != otp.MD5 && alg != otp.SHA1 {
+		return fmt.Errorf("unsupported algorithm %v", alg)
+	}
+	return nil
+}
+
+func checkSeed(seed string) error {
+	if seed == "" || len(seed) > MaxSeedLength {
+		return fmt.Errorf("seed must have 1 to %d characters", MaxSeedLength)
+	}
+	for _, c := range seed {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return fmt.Errorf("invalid seed character %q", c)
+		}
+	}
+	return nil
+}
+
+// fold hashes data with alg and folds the digest to 64 bits as specified by
+// RFC 2289 Appendix A.
+func fold(alg otp.Algorithm, data []byte) Value {
+	h := alg.New()
+	h.Write(data)
+	sum := h.Sum(nil)
+
+	var v Value
+	switch alg {
+	case otp.MD5:
+		for i := range v {
+			v[i] = sum[i] ^ sum[i+8]
+		}
+	case otp.SHA1:
+		// The 32-bit words are folded and then stored in little-endian order,
+		// per the reference implementation in the RFC.
+		w0 := binary.BigEndian.Uint32(sum[0:]) ^ binary.BigEndian.Uint32(sum[8:]) ^ binary.BigEndian.Uint32(sum[16:])
+		w1 := binary.BigEndian.Uint32(sum[4:]) ^ binary.BigEndian.Uint32(sum[12:])
+		binary.LittleEndian.PutUint32(v[0:], w0)
+		binary.LittleEndian.PutUint32(v[4:], w1)
+	}
+	return v
+}
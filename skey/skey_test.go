@@ -0,0 +1,197 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package skey_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/creachadair/otp"
+	"github.com/creachadair/otp/skey"
+)
+
+// Test vectors from RFC 2289 Appendix C.
+var rfcTests = []struct {
+	alg              otp.Algorithm
+	passphrase, seed string
+	seq              int
+	hex, words       string
+}{
+	{otp.MD5, "This is a test.", "TeSt", 0, "9E876134D90499DD", "INCH SEA ANNE LONG AHEM TOUR"},
+	{otp.MD5, "This is a test.", "TeSt", 1, "7965E05436F5029F", "EASE OIL FUM CURE AWRY AVIS"},
+	{otp.MD5, "This is a test.", "TeSt", 99, "50FE1962C4965880", "BAIL TUFT BITS GANG CHEF THY"},
+	{otp.MD5, "AbCdEfGhIjK", "alpha1", 0, "87066DD9644BF206", "FULL PEW DOWN ONCE MORT ARC"},
+	{otp.MD5, "AbCdEfGhIjK", "alpha1", 1, "7CD34C1040ADD14B", "FACT HOOF AT FIST SITE KENT"},
+	{otp.MD5, "AbCdEfGhIjK", "alpha1", 99, "5AA37A81F212146C", "BODE HOP JAKE STOW JUT RAP"},
+	{otp.MD5, "OTP's are good", "correct", 0, "F205753943DE4CF9", "ULAN NEW ARMY FUSE SUIT EYED"},
+	{otp.MD5, "OTP's are good", "correct", 1, "DDCDAC956F234937", "SKIM CULT LOB SLAM POE HOWL"},
+	{otp.MD5, "OTP's are good", "correct", 99, "B203E28FA525BE47", "LONG IVY JULY AJAR BOND LEE"},
+
+	{otp.SHA1, "This is a test.", "TeSt", 0, "BB9E6AE1979D8FF4", "MILT VARY MAST OK SEES WENT"},
+	{otp.SHA1, "This is a test.", "TeSt", 1, "63D936639734385B", "CART OTTO HIVE ODE VAT NUT"},
+	{otp.SHA1, "This is a test.", "TeSt", 99, "87FEC7768B73CCF9", "GAFF WAIT SKID GIG SKY EYED"},
+	{otp.SHA1, "AbCdEfGhIjK", "alpha1", 0, "AD85F658EBE383C9", "LEST OR HEEL SCOT ROB SUIT"},
+	{otp.SHA1, "AbCdEfGhIjK", "alpha1", 1, "D07CE229B5CF119B", "RITE TAKE GELD COST TUNE RECK"},
+	{otp.SHA1, "AbCdEfGhIjK", "alpha1", 99, "27BC71035AAF3DC6", "MAY STAR TIN LYON VEDA STAN"},
+	{otp.SHA1, "OTP's are good", "correct", 0, "D51F3E99BF8E6F0B", "RUST WELT KICK FELL TAIL FRAU"},
+	{otp.SHA1, "OTP's are good", "correct", 1, "82AEB52D943774E4", "FLIT DOSE ALSO MEW DRUM DEFY"},
+	{otp.SHA1, "OTP's are good", "correct", 99, "4F296A74FE1567EC", "AURA ALOE HURL WING BERG WAIT"},
+}
+
+func TestCompute(t *testing.T) {
+	for _, tc := range rfcTests {
+		v, err := skey.Compute(tc.alg, tc.passphrase, tc.seed, tc.seq)
+		if err != nil {
+			t.Errorf("Compute(%v, %q, %q, %d): unexpected error: %v", tc.alg, tc.passphrase, tc.seed, tc.seq, err)
+			continue
+		}
+		if got := v.Hex(); got != tc.hex {
+			t.Errorf("Compute(%v, %q, %q, %d): got hex %q, want %q", tc.alg, tc.passphrase, tc.seed, tc.seq, got, tc.hex)
+		}
+		if got := v.Words(); got != tc.words {
+			t.Errorf("Compute(%v, %q, %q, %d): got words %q, want %q", tc.alg, tc.passphrase, tc.seed, tc.seq, got, tc.words)
+		}
+
+		// Both formats should parse back to the same value.
+		for _, s := range []string{tc.hex, tc.words, strings.ToLower(tc.words)} {
+			p, err := skey.ParseValue(s)
+			if err != nil {
+				t.Errorf("ParseValue(%q): unexpected error: %v", s, err)
+			} else if p != v {
+				t.Errorf("ParseValue(%q): got %v, want %v", s, p, v)
+			}
+		}
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	want, err := skey.ParseValue("9E876134D90499DD")
+	if err != nil {
+		t.Fatalf("ParseValue: unexpected error: %v", err)
+	}
+	for _, s := range []string{
+		"9e87 6134 d904 99dd",
+		"  INCH SEA  ANNE LONG AHEM TOUR ",
+		"inch 5ea anne 10ng ahem t0ur", // digits for letters
+	} {
+		if got, err := skey.ParseValue(s); err != nil {
+			t.Errorf("ParseValue(%q): unexpected error: %v", s, err)
+		} else if got != want {
+			t.Errorf("ParseValue(%q): got %v, want %v", s, got, want)
+		}
+	}
+	for _, bad := range []string{
+		"",
+		"9E876134D90499",
+		"9E876134D90499DDFF",
+		"not hex at all",
+		"INCH SEA ANNE LONG AHEM TOUT", // bad checksum
+		"INCH SEA ANNE LONG AHEM XYZZY",
+	} {
+		if v, err := skey.ParseValue(bad); err == nil {
+			t.Errorf("ParseValue(%q): got %v, want error", bad, v)
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	const passphrase = "This is a test."
+	vs, err := skey.Sequence(otp.MD5, passphrase, "TeSt", 99, 100)
+	if err != nil {
+		t.Fatalf("Sequence: unexpected error: %v", err)
+	}
+	if len(vs) != 100 {
+		t.Fatalf("Sequence: got %d values, want 100", len(vs))
+	}
+	for i, want := range map[int]string{
+		0:  "50FE1962C4965880", // seq 99
+		98: "7965E05436F5029F", // seq 1
+		99: "9E876134D90499DD", // seq 0
+	} {
+		if got := vs[i].Hex(); got != want {
+			t.Errorf("Sequence[%d]: got %s, want %s", i, got, want)
+		}
+	}
+
+	// Each value should be the hash of the one following it.
+	st, err := skey.NewState(otp.MD5, passphrase, "TeSt", 99)
+	if err != nil {
+		t.Fatalf("NewState: unexpected error: %v", err)
+	}
+	for i, v := range vs[1:] {
+		if err := st.Verify(v.Hex()); err != nil {
+			t.Fatalf("Verify [%d]: unexpected error: %v", i+1, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		alg              otp.Algorithm
+		passphrase, seed string
+		seq, n           int
+	}{
+		{otp.SHA256, passphrase, "TeSt", 1, 1},           // unsupported algorithm
+		{otp.MD5, "too short", "TeSt", 1, 1},             // short passphrase
+		{otp.MD5, passphrase, "", 1, 1},                  // empty seed
+		{otp.MD5, passphrase, "te st", 1, 1},             // invalid seed
+		{otp.MD5, passphrase, "seedseedseedseeds", 1, 1}, // long seed
+		{otp.MD5, passphrase, "TeSt", -1, 0},
+		{otp.MD5, passphrase, "TeSt", 5, 7},
+	} {
+		if vs, err := skey.Sequence(tc.alg, tc.passphrase, tc.seed, tc.seq, tc.n); err == nil {
+			t.Errorf("Sequence(%v, %q, %q, %d, %d): got %v, want error", tc.alg, tc.passphrase, tc.seed, tc.seq, tc.n, vs)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	const passphrase = "This is a test."
+	st, err := skey.NewState(otp.MD5, passphrase, "TeSt", 3)
+	if err != nil {
+		t.Fatalf("NewState: unexpected error: %v", err)
+	}
+	if got, want := st.Challenge(), "otp-md5 2 test"; got != want {
+		t.Errorf("Challenge: got %q, want %q", got, want)
+	}
+
+	// A password other than the next one is rejected.
+	v0, _ := skey.Compute(otp.MD5, passphrase, "TeSt", 0)
+	if err := st.Verify(v0.Words()); !errors.Is(err, skey.ErrInvalid) {
+		t.Errorf("Verify(seq 0): got %v, want %v", err, skey.ErrInvalid)
+	}
+	if err := st.Verify("bogus"); !errors.Is(err, skey.ErrInvalid) {
+		t.Errorf("Verify(bogus): got %v, want %v", err, skey.ErrInvalid)
+	}
+
+	for seq := 2; seq >= 0; seq-- {
+		v, _ := skey.Compute(otp.MD5, passphrase, "TeSt", seq)
+		resp := v.Hex()
+		if seq%2 == 0 {
+			resp = v.Words()
+		}
+		if err := st.Verify(resp); err != nil {
+			t.Errorf("Verify(seq %d): unexpected error: %v", seq, err)
+		}
+		if st.Seq != seq || st.Last != v {
+			t.Errorf("After Verify(seq %d): got state %+v", seq, st)
+		}
+		// The same password is not accepted again.
+		if err := st.Verify(resp); err == nil {
+			t.Errorf("Verify(seq %d) again: got nil, want error", seq)
+		}
+	}
+	if err := st.Verify(v0.Hex()); !errors.Is(err, skey.ErrExhausted) {
+		t.Errorf("Verify after exhaustion: got %v, want %v", err, skey.ErrExhausted)
+	}
+
+	sha, err := skey.NewState(otp.SHA1, passphrase, "TeSt", 100)
+	if err != nil {
+		t.Fatalf("NewState: unexpected error: %v", err)
+	}
+	if got, want := sha.Challenge(), "otp-sha1 99 test"; got != want {
+		t.Errorf("Challenge: got %q, want %q", got, want)
+	}
+	if err := sha.Verify("GAFF WAIT SKID GIG SKY EYED"); err != nil {
+		t.Errorf("Verify: unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp
+
+import (
+	"encoding/base64"
+	"time"
+)
+
+// SteamAlphabet is the alphabet used to format Steam Guard codes.
+const SteamAlphabet = "23456789BCDFGHJKMNPQRTVWXY"
+
+// SteamDigits is the number of characters in a Steam Guard code.
+const SteamDigits = 5
+
+// SteamConfig returns a Config template with the settings used by Steam Guard:
+// HMAC-SHA1 over a 30-second time step, formatted by FormatSteam as 5
+// characters. The Key field is empty; use WithKey or set it directly.
+func SteamConfig() Config {
+	return Config{
+		Algorithm: SHA1,
+		Period:    30 * time.Second,
+		Digits:    SteamDigits,
+		Format:    FormatSteam,
+	}
+}
+
+// FormatSteam is a formatting function that truncates the counter hash per
+// RFC 4226 and renders it as a Steam Guard code using the letters of
+// SteamAlphabet. Unlike FormatAlphabet, code digits are expanded from least to
+// most significant, as Steam Guard does.
+func FormatSteam(hmac []byte, width int) string {
+	code := Truncate(hmac)
+	w := uint64(len(SteamAlphabet))
+	out := make([]byte, width)
+	for i := range out {
+		out[i] = SteamAlphabet[int(code%w)]
+		code /= w
+	}
+	return string(out)
+}
+
+// SteamConfirmationKey computes the key that authorizes a Steam mobile
+// confirmation request at time t, using the account's identity secret. The tag
+// names the operation, e.g., "conf" to list confirmations, or "allow" and
+// "cancel" to respond to one; only the first 32 bytes of the tag are used.
+// The result is the base64 encoding of the HMAC-SHA1 of the Unix time t
+// followed by the tag.
+func SteamConfirmationKey(identitySecret []byte, t time.Time, tag string) string {
+	if len(tag) > 32 {
+		tag = tag[:32]
+	}
+	c := Config{Key: string(identitySecret), Algorithm: SHA1}
+	return base64.StdEncoding.EncodeToString(c.hmacSuffix(uint64(t.Unix()), []byte(tag)))
+}
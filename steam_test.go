@@ -0,0 +1,56 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creachadair/otp"
+)
+
+func TestSteamConfig(t *testing.T) {
+	// Reference codes from the ValvePython/steam library.
+	cfg := otp.SteamConfig()
+	cfg.Key = "superdupersecret"
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{3000030, "YRGQJ"},
+		{3000029, "94R9D"},
+	}
+	for _, tc := range tests {
+		if got := cfg.TOTPAt(time.Unix(tc.unix, 0)); got != tc.want {
+			t.Errorf("TOTPAt(%d): got %q, want %q", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestSteamConfirmationKey(t *testing.T) {
+	// Reference keys from the ValvePython/steam library, which reports them as
+	// raw bytes; they are base64-encoded here.
+	secret := []byte("itsmemario")
+	tests := []struct {
+		unix int64
+		tag  string
+		want string
+	}{
+		{100000, "", "7bXlrY/xmQHILXfWtSBwzHrX0QU="},
+		{100000, "allow", "UScGgOFnqG0ksmhW5meLJ4/xTLA="},
+	}
+	for _, tc := range tests {
+		got := otp.SteamConfirmationKey(secret, time.Unix(tc.unix, 0), tc.tag)
+		if got != tc.want {
+			t.Errorf("SteamConfirmationKey(%d, %q): got %q, want %q", tc.unix, tc.tag, got, tc.want)
+		}
+	}
+
+	// Tags longer than 32 bytes are truncated.
+	long := "details" + strings.Repeat("x", 40)
+	now := time.Unix(1700000000, 0)
+	if got, want := otp.SteamConfirmationKey(secret, now, long), otp.SteamConfirmationKey(secret, now, long[:32]); got != want {
+		t.Errorf("SteamConfirmationKey(long tag): got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrThrottled is reported when a verification attempt is refused because the
+// subject has failed too many recent attempts. The concrete type of such an
+// error is *ThrottleError, which reports when the subject may try again.
+var ErrThrottled = errors.New("too many failed attempts")
+
+// ThrottleError is the concrete type of errors reported by a Throttle.
+// It satisfies errors.Is(err, ErrThrottled).
+type ThrottleError struct {
+	Subject    string        // the subject that was throttled
+	RetryAfter time.Duration // how long until the subject may try again
+}
+
+// Error satisfies the error interface.
+func (e *ThrottleError) Error() string {
+	return fmt.Sprintf("subject %q: %v (retry after %v)", e.Subject, ErrThrottled, e.RetryAfter)
+}
+
+// Unwrap reports ErrThrottled, so that errors.Is(e, ErrThrottled) is true.
+func (e *ThrottleError) Unwrap() error { return ErrThrottled }
+
+// A Throttle tracks failed verification attempts for each subject, and locks
+// out a subject that accumulates too many consecutive failures, as described
+// in RFC 4226 Section 7.3. A zero Throttle is ready for use with default
+// settings. A Throttle is safe for concurrent use by multiple goroutines.
+type Throttle struct {
+	// MaxFailures is the number of consecutive failed attempts permitted before
+	// a subject is locked out. If zero or negative, the default is 5.
+	MaxFailures int
+
+	// Lockout is the duration for which a subject is locked out after
+	// reaching MaxFailures. If zero or negative, the default is 1 minute.
+	Lockout time.Duration
+
+	// If Backoff is true, the lockout duration doubles for each further
+	// failure after MaxFailures is reached, up to MaxLockout. Otherwise, each
+	// failure after MaxFailures is reached imposes the same fixed Lockout.
+	Backoff bool
+
+	// MaxLockout is the longest lockout imposed when Backoff is true.
+	// If zero or negative, the default is 1 hour.
+	MaxLockout time.Duration
+
+	// ResetAfter is the time after a subject's most recent failure at which
+	// its failures are forgotten, provided it is not locked out. Forgotten
+	// subjects no longer use any memory. If zero or negative, the default is
+	// 24 hours.
+	ResetAfter time.Duration
+
+	// Now, if non-nil, is called to obtain the current time.
+	// If nil, time.Now is used.
+	Now func() time.Time
+
+	μ       sync.Mutex
+	state   map[string]*throttleState
+	sweepAt int // sweep stale state when len(state) reaches this size
+}
+
+type throttleState struct {
+	failures int       // consecutive failures since the last success
+	pending  int       // attempts begun but not yet resolved
+	last     time.Time // the time of the most recent failure
+	until    time.Time // locked out until this time
+}
+
+// Check reports a *ThrottleError if subject is currently locked out, or nil
+// if subject may make an attempt.
+//
+// Check does not reserve an attempt, so concurrent callers that each call
+// Check and then Failure may together exceed MaxFailures. Use Begin to check
+// and record an attempt atomically.
+func (t *Throttle) Check(subject string) error {
+	t.μ.Lock()
+	defer t.μ.Unlock()
+	now := t.now()
+	if st := t.getLocked(subject, now, false); st != nil {
+		return t.checkLocked(subject, st, now)
+	}
+	return nil
+}
+
+// Failure records a failed attempt for subject. If this failure causes the
+// subject to be locked out, it returns a *ThrottleError; otherwise nil.
+func (t *Throttle) Failure(subject string) error {
+	t.μ.Lock()
+	defer t.μ.Unlock()
+	now := t.now()
+	st := t.getLocked(subject, now, true)
+	t.failLocked(st, now)
+	return t.checkLocked(subject, st, now)
+}
+
+// Success records a successful attempt for subject, clearing its failures.
+func (t *Throttle) Success(subject string) {
+	t.μ.Lock()
+	defer t.μ.Unlock()
+	if st, ok := t.state[subject]; ok {
+		t.clearLocked(subject, st)
+	}
+}
+
+// Begin atomically checks whether subject may make an attempt and, if so,
+// reserves the attempt by recording it as a failure in advance. This ensures
+// that concurrent attempts cannot together exceed MaxFailures before their
+// outcomes are known. If subject is locked out, Begin reports a
+// *ThrottleError and no attempt is reserved.
+//
+// The caller must resolve the attempt by calling exactly one of its methods.
+func (t *Throttle) Begin(subject string) (*ThrottleAttempt, error) {
+	t.μ.Lock()
+	defer t.μ.Unlock()
+	now := t.now()
+	st := t.getLocked(subject, now, true)
+	if err := t.checkLocked(subject, st, now); err != nil {
+		return nil, err
+	}
+	a := &ThrottleAttempt{t: t, subject: subject, st: st, prev: st.until}
+	t.failLocked(st, now)
+	st.pending++
+	a.until = st.until
+	return a, nil
+}
+
+// A ThrottleAttempt is an attempt reserved by [Throttle.Begin].
+type ThrottleAttempt struct {
+	t       *Throttle
+	subject string
+	st      *throttleState
+	prev    time.Time // the lockout in effect before the attempt
+	until   time.Time // the lockout in effect after the attempt
+}
+
+// Succeeded records that the attempt succeeded, clearing the subject's
+// failures as [Throttle.Success] does.
+func (a *ThrottleAttempt) Succeeded() {
+	a.t.μ.Lock()
+	defer a.t.μ.Unlock()
+	a.st.pending--
+	a.t.clearLocked(a.subject, a.st)
+}
+
+// Failed records that the attempt failed. If the subject is locked out as a
+// result of the attempt, it returns a *ThrottleError; otherwise nil.
+func (a *ThrottleAttempt) Failed() error {
+	a.t.μ.Lock()
+	defer a.t.μ.Unlock()
+	a.st.pending--
+	return a.t.checkLocked(a.subject, a.st, a.t.now())
+}
+
+// Cancel records that the attempt was not made, for example because the code
+// could not be checked. The failure reserved by Begin is withdrawn.
+func (a *ThrottleAttempt) Cancel() {
+	a.t.μ.Lock()
+	defer a.t.μ.Unlock()
+	a.st.pending--
+	if a.st.failures > 0 {
+		a.st.failures--
+	}
+	if a.st.until.Equal(a.until) {
+		a.st.until = a.prev
+	}
+}
+
+// getLocked returns the state for subject, or nil if there is none and create
+// is false. Stale state is discarded. The caller must hold t.μ.
+func (t *Throttle) getLocked(subject string, now time.Time, create bool) *throttleState {
+	st, ok := t.state[subject]
+	if ok && t.isStale(st, now) {
+		delete(t.state, subject)
+		st, ok = nil, false
+	}
+	if !ok && create {
+		if t.state == nil {
+			t.state = make(map[string]*throttleState)
+		}
+		if len(t.state) >= t.sweepAt {
+			t.sweepLocked(now)
+		}
+		st = new(throttleState)
+		t.state[subject] = st
+	}
+	return st
+}
+
+// sweepLocked discards all stale state. The caller must hold t.μ.
+func (t *Throttle) sweepLocked(now time.Time) {
+	for subject, st := range t.state {
+		if t.isStale(st, now) {
+			delete(t.state, subject)
+		}
+	}
+	t.sweepAt = max(64, 2*len(t.state))
+}
+
+// isStale reports whether st can be forgotten: No attempts are pending, the
+// subject is not locked out, and its failures have expired.
+func (t *Throttle) isStale(st *throttleState, now time.Time) bool {
+	return st.pending == 0 && !now.Before(st.until) && now.Sub(st.last) >= t.resetAfter()
+}
+
+// failLocked records a failure in st. The caller must hold t.μ.
+func (t *Throttle) failLocked(st *throttleState, now time.Time) {
+	st.failures++
+	st.last = now
+	if excess := st.failures - t.maxFailures(); excess >= 0 {
+		st.until = now.Add(t.lockout(excess))
+	}
+}
+
+// clearLocked clears the failures of subject. Failures reserved by pending
+// attempts are retained. The caller must hold t.μ.
+func (t *Throttle) clearLocked(subject string, st *throttleState) {
+	st.failures = st.pending
+	st.until = time.Time{}
+	if st.pending == 0 && t.state[subject] == st {
+		delete(t.state, subject)
+	}
+}
+
+func (t *Throttle) checkLocked(subject string, st *throttleState, now time.Time) error {
+	if now.Before(st.until) {
+		return &ThrottleError{Subject: subject, RetryAfter: st.until.Sub(now)}
+	}
+	return nil
+}
+
+// lockout returns the lockout duration for a subject that has exceeded the
+// maximum number of failures by excess.
+func (t *Throttle) lockout(excess int) time.Duration {
+	d := t.Lockout
+	if d <= 0 {
+		d = time.Minute
+	}
+	if !t.Backoff {
+		return d
+	}
+	limit := t.MaxLockout
+	if limit <= 0 {
+		limit = time.Hour
+	}
+	for range excess {
+		if d >= limit/2 {
+			return limit
+		}
+		d *= 2
+	}
+	return min(d, limit)
+}
+
+func (t *Throttle) maxFailures() int {
+	if t.MaxFailures <= 0 {
+		return 5
+	}
+	return t.MaxFailures
+}
+
+func (t *Throttle) resetAfter() time.Duration {
+	if t.ResetAfter <= 0 {
+		return 24 * time.Hour
+	}
+	return t.ResetAfter
+}
+
+func (t *Throttle) now() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
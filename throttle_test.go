@@ -0,0 +1,237 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/creachadair/otp"
+)
+
+// fakeClock is a manually-advanced clock for testing.
+type fakeClock struct{ now time.Time }
+
+func (f *fakeClock) Now() time.Time          { return f.now }
+func (f *fakeClock) Advance(d time.Duration) { f.now = f.now.Add(d) }
+
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+	var terr *otp.ThrottleError
+	if !errors.As(err, &terr) {
+		t.Fatalf("Got error %v, want *ThrottleError", err)
+	}
+	if !errors.Is(err, otp.ErrThrottled) {
+		t.Errorf("Error %v is not ErrThrottled", err)
+	}
+	return terr.RetryAfter
+}
+
+func TestThrottle_Fixed(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(1000, 0)}
+	th := &otp.Throttle{MaxFailures: 3, Lockout: 30 * time.Second, Now: clk.Now}
+
+	for i := range 2 {
+		if err := th.Failure("alice"); err != nil {
+			t.Fatalf("Failure %d: unexpected error: %v", i+1, err)
+		}
+	}
+	if err := th.Check("alice"); err != nil {
+		t.Fatalf("Check: unexpected error: %v", err)
+	}
+	if got := retryAfter(t, th.Failure("alice")); got != 30*time.Second {
+		t.Errorf("Failure 3: retry after %v, want 30s", got)
+	}
+
+	// Other subjects are not affected.
+	if err := th.Check("bob"); err != nil {
+		t.Errorf("Check bob: unexpected error: %v", err)
+	}
+
+	clk.Advance(10 * time.Second)
+	if got := retryAfter(t, th.Check("alice")); got != 20*time.Second {
+		t.Errorf("Check: retry after %v, want 20s", got)
+	}
+
+	// After the lockout expires, the next failure locks out again.
+	clk.Advance(20 * time.Second)
+	if err := th.Check("alice"); err != nil {
+		t.Errorf("Check after lockout: unexpected error: %v", err)
+	}
+	if got := retryAfter(t, th.Failure("alice")); got != 30*time.Second {
+		t.Errorf("Failure 4: retry after %v, want 30s", got)
+	}
+
+	// A success clears the record.
+	clk.Advance(time.Minute)
+	th.Success("alice")
+	if err := th.Failure("alice"); err != nil {
+		t.Errorf("Failure after success: unexpected error: %v", err)
+	}
+}
+
+func TestThrottle_Backoff(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(1000, 0)}
+	th := &otp.Throttle{
+		MaxFailures: 1,
+		Lockout:     time.Second,
+		Backoff:     true,
+		MaxLockout:  10 * time.Second,
+		Now:         clk.Now,
+	}
+	for _, want := range []time.Duration{1, 2, 4, 8, 10, 10} {
+		want *= time.Second
+		got := retryAfter(t, th.Failure("alice"))
+		if got != want {
+			t.Errorf("Failure: retry after %v, want %v", got, want)
+		}
+		clk.Advance(got)
+	}
+}
+
+func TestVerifier_Throttle(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(1000, 0)}
+	v := otp.Verifier{
+		Config:   otp.Config{Key: "12345678901234567890", TimeStep: fixedTime(50)},
+		Subject:  "alice",
+		Store:    new(otp.MemStepStore),
+		Throttle: &otp.Throttle{MaxFailures: 2, Lockout: time.Minute, Now: clk.Now},
+	}
+	good := v.Config.HOTP(50)
+
+	if _, err := v.VerifyTOTP("bogus"); !errors.Is(err, otp.ErrInvalidCode) {
+		t.Fatalf("VerifyTOTP: got %v, want %v", err, otp.ErrInvalidCode)
+	}
+	if _, err := v.VerifyTOTP(good); err != nil {
+		t.Fatalf("VerifyTOTP: unexpected error: %v", err)
+	}
+
+	// A replay counts as a failure, and the second consecutive failure locks
+	// out the subject while still reporting the underlying cause.
+	if _, err := v.VerifyTOTP("bogus"); !errors.Is(err, otp.ErrInvalidCode) {
+		t.Fatalf("VerifyTOTP: got %v, want %v", err, otp.ErrInvalidCode)
+	}
+	_, err := v.VerifyTOTP(good)
+	if !errors.Is(err, otp.ErrReplayed) {
+		t.Errorf("VerifyTOTP: got %v, want %v", err, otp.ErrReplayed)
+	}
+	if got := retryAfter(t, err); got != time.Minute {
+		t.Errorf("VerifyTOTP: retry after %v, want 1m", got)
+	}
+
+	// While locked out, even a valid code is refused.
+	v.Config.TimeStep = fixedTime(51)
+	if _, err := v.VerifyTOTP(v.Config.HOTP(51)); !errors.Is(err, otp.ErrThrottled) {
+		t.Errorf("VerifyTOTP: got %v, want %v", err, otp.ErrThrottled)
+	}
+	clk.Advance(time.Minute)
+	if _, err := v.VerifyTOTP(v.Config.HOTP(51)); err != nil {
+		t.Errorf("VerifyTOTP: unexpected error: %v", err)
+	}
+}
+
+func TestThrottle_Begin(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(1000, 0)}
+	th := &otp.Throttle{MaxFailures: 3, Lockout: time.Minute, Now: clk.Now}
+
+	// Attempts are counted when they begin, so no more than MaxFailures can be
+	// in flight at once, even before any outcome is known.
+	var atts []*otp.ThrottleAttempt
+	for i := range 3 {
+		a, err := th.Begin("alice")
+		if err != nil {
+			t.Fatalf("Begin %d: unexpected error: %v", i+1, err)
+		}
+		atts = append(atts, a)
+	}
+	if _, err := th.Begin("alice"); !errors.Is(err, otp.ErrThrottled) {
+		t.Fatalf("Begin 4: got %v, want %v", err, otp.ErrThrottled)
+	}
+
+	// Cancelling the attempt that caused the lockout withdraws it.
+	atts[2].Cancel()
+	a, err := th.Begin("alice")
+	if err != nil {
+		t.Fatalf("Begin after Cancel: unexpected error: %v", err)
+	}
+	if got := retryAfter(t, a.Failed()); got != time.Minute {
+		t.Errorf("Failed: retry after %v, want 1m", got)
+	}
+
+	// A success clears the lockout, but the failures reserved by attempts
+	// still pending are kept.
+	atts[0].Succeeded()
+	if err := th.Check("alice"); err != nil {
+		t.Errorf("Check after success: unexpected error: %v", err)
+	}
+	if err := atts[1].Failed(); err != nil {
+		t.Errorf("Failed: unexpected error: %v", err)
+	}
+	for i := range 2 {
+		a, err := th.Begin("alice")
+		if err != nil {
+			t.Fatalf("Begin %d: unexpected error: %v", i+1, err)
+		}
+		err = a.Failed()
+		if i == 0 && err != nil {
+			t.Errorf("Failed %d: unexpected error: %v", i+1, err)
+		} else if i == 1 && !errors.Is(err, otp.ErrThrottled) {
+			t.Errorf("Failed %d: got %v, want %v", i+1, err, otp.ErrThrottled)
+		}
+	}
+}
+
+func TestThrottle_Concurrent(t *testing.T) {
+	th := &otp.Throttle{MaxFailures: 5, Lockout: time.Hour}
+
+	var wg sync.WaitGroup
+	var μ sync.Mutex
+	var attempts int
+	for range 50 {
+		wg.Go(func() {
+			a, err := th.Begin("alice")
+			if err != nil {
+				return
+			}
+			μ.Lock()
+			attempts++
+			μ.Unlock()
+			a.Failed()
+		})
+	}
+	wg.Wait()
+	if attempts != 5 {
+		t.Errorf("Concurrent attempts: got %d, want 5", attempts)
+	}
+}
+
+func TestThrottle_Reset(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(1000, 0)}
+	th := &otp.Throttle{MaxFailures: 2, Lockout: time.Minute, ResetAfter: time.Hour, Now: clk.Now}
+
+	if err := th.Failure("alice"); err != nil {
+		t.Fatalf("Failure: unexpected error: %v", err)
+	}
+
+	// After ResetAfter has elapsed, the earlier failure is forgotten.
+	clk.Advance(time.Hour)
+	if err := th.Failure("alice"); err != nil {
+		t.Fatalf("Failure after reset: unexpected error: %v", err)
+	}
+	if err := th.Failure("alice"); !errors.Is(err, otp.ErrThrottled) {
+		t.Fatalf("Failure: got %v, want %v", err, otp.ErrThrottled)
+	}
+
+	// A lockout is not forgotten before it expires, even if it outlasts
+	// ResetAfter.
+	th.Lockout = 2 * time.Hour
+	if err := th.Failure("alice"); !errors.Is(err, otp.ErrThrottled) {
+		t.Fatalf("Failure: got %v, want %v", err, otp.ErrThrottled)
+	}
+	clk.Advance(90 * time.Minute)
+	if err := th.Check("alice"); !errors.Is(err, otp.ErrThrottled) {
+		t.Errorf("Check: got %v, want %v", err, otp.ErrThrottled)
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp
+
+import (
+	"crypto/subtle"
+	"errors"
+)
+
+// ErrInvalidCode is reported by a Verifier when a code does not match.
+var ErrInvalidCode = errors.New("invalid code")
+
+// VerifyOptions control the verification of codes.
+// A nil *VerifyOptions is ready for use and accepts only exact matches.
+type VerifyOptions struct {
+	// Before is the number of time steps prior to the current step that are
+	// also accepted when verifying a TOTP code, to allow for clock skew and
+	// transmission delay. RFC 6238 recommends at most one step.
+	Before int
+
+	// After is the number of time steps following the current step that are
+	// also accepted when verifying a TOTP code.
+	After int
+
+	// LookAhead is the number of counter values following the next expected
+	// value that are also accepted when verifying an HOTP code, to allow for
+	// codes generated but never submitted. If zero, only the next expected
+	// counter is accepted.
+	LookAhead int
+
+	// ResyncWindow is the number of counter values searched when
+	// resynchronizing an HOTP counter from consecutive codes.
+	// If zero, the default is 100.
+	ResyncWindow int
+}
+
+func (o *VerifyOptions) before() int {
+	if o == nil || o.Before < 0 {
+		return 0
+	}
+	return o.Before
+}
+
+func (o *VerifyOptions) after() int {
+	if o == nil || o.After < 0 {
+		return 0
+	}
+	return o.After
+}
+
+func (o *VerifyOptions) lookAhead() int {
+	if o == nil || o.LookAhead < 0 {
+		return 0
+	}
+	return o.LookAhead
+}
+
+func (o *VerifyOptions) resyncWindow() int {
+	if o == nil || o.ResyncWindow <= 0 {
+		return 100
+	}
+	return o.ResyncWindow
+}
+
+// VerifyTOTP reports whether code is a valid TOTP code for the current time
+// step, or for one of the steps permitted by opts. If so, it also returns the
+// offset of the matching step relative to the current step: 0 for the current
+// step, negative for earlier steps, and positive for later steps.
+//
+// Every candidate step is checked, and codes are compared in constant time,
+// so that the time taken does not depend on which step (if any) matched.
+func (c Config) VerifyTOTP(code string, opts *VerifyOptions) (int, bool) {
+	return verifyWindow(c.HOTP, code, c.timeStepWindow(), opts.before(), opts.after())
+}
+
+// VerifyHOTP reports whether code is a valid HOTP code for the next expected
+// counter value, c.Counter+1, or for one of the following counter values
+// permitted by the LookAhead setting of opts. If so, it returns the matching
+// counter value, which the caller should store as the new value of c.Counter
+// so that the same code cannot be accepted again.
+//
+// This implements the look-ahead verification described in RFC 4226 Section
+// 7.4. Codes are compared in constant time.
+func (c Config) VerifyHOTP(code string, opts *VerifyOptions) (uint64, bool) {
+	off, ok := verifyWindow(c.HOTP, code, c.Counter+1, 0, opts.lookAhead())
+	if !ok {
+		return 0, false
+	}
+	return c.Counter + 1 + uint64(off), true
+}
+
+// ResyncHOTP searches for a counter value n following c.Counter such that
+// code1 is the HOTP code for n and code2 is the HOTP code for n+1, examining
+// at most the number of counter values specified by the ResyncWindow setting
+// of opts. If such a value is found, it returns n+1, which the caller should
+// store as the new value of c.Counter.
+//
+// This is the resynchronization procedure described in RFC 4226 Section 7.4,
+// for use when a token has drifted beyond the normal look-ahead window.
+// The search window should be larger than the LookAhead used by VerifyHOTP,
+// but requiring two consecutive codes keeps the chance of a false match low.
+func (c Config) ResyncHOTP(code1, code2 string, opts *VerifyOptions) (uint64, bool) {
+	n := opts.resyncWindow()
+	for i := 1; i <= n; i++ {
+		ctr := c.Counter + uint64(i)
+		if ctr < c.Counter || ctr+1 == 0 {
+			break // past the end of the counter space
+		}
+		if equalCode(c.HOTP(ctr), code1) && equalCode(c.HOTP(ctr+1), code2) {
+			return ctr + 1, true
+		}
+	}
+	return 0, false
+}
+
+// verifyWindow checks code against the codes generated by gen for the steps
+// from step-before to step+after inclusive, omitting any steps that would
+// overflow. It reports the offset of the earliest matching step relative to
+// step.
+func verifyWindow(gen func(uint64) string, code string, step uint64, before, after int) (int, bool) {
+	var offset int
+	var found bool
+	for i := -before; i <= after; i++ {
+		if i < 0 && uint64(-i) > step {
+			continue // before the start of time
+		} else if i > 0 && step+uint64(i) < step {
+			break // past the end of time
+		}
+		if equalCode(gen(step+uint64(int64(i))), code) && !found {
+			offset, found = i, true
+		}
+	}
+	return offset, found
+}
+
+// equalCode reports whether want and got are equal, in constant time.
+func equalCode(want, got string) bool {
+	return subtle.ConstantTimeCompare([]byte(want), []byte(got)) == 1
+}
+
+// A Verifier checks codes presented by a single subject, and uses a
+// UsedStepStore to reject codes that have already been accepted.
+type Verifier struct {
+	Config  Config         // settings and key for the subject
+	Subject string         // identifies the subject to the Store
+	Options *VerifyOptions // skew and look-ahead settings
+
+	// Store, if non-nil, records the steps accepted for the subject, and a
+	// code is rejected unless its step is later than the last one accepted.
+	// If nil, replayed codes are not detected.
+	//
+	// A store should not be shared between TOTP and HOTP verification for the
+	// same subject, since time steps and counter values are not comparable.
+	Store UsedStepStore
+
+	// Throttle, if non-nil, is consulted before each attempt, and records the
+	// outcome of each attempt for the subject. While the subject is locked
+	// out, attempts are refused with a *ThrottleError without being checked.
+	// Each attempt is reserved with [Throttle.Begin], so concurrent attempts
+	// cannot exceed the limit on failures. If nil, attempts are not throttled.
+	Throttle *Throttle
+}
+
+// VerifyTOTP checks code as a TOTP code for the current time step, subject to
+// the skew settings of v.Options. On success it returns the offset of the
+// matching step as for [Config.VerifyTOTP], and records the step in v.Store.
+// If code does not match, it reports ErrInvalidCode; if the step matched was
+// already used, it reports ErrReplayed. If the subject is throttled, it
+// reports a *ThrottleError.
+func (v Verifier) VerifyTOTP(code string) (int, error) {
+	att, err := v.begin()
+	if err != nil {
+		return 0, err
+	}
+	step := v.Config.timeStepWindow()
+	off, ok := verifyWindow(v.Config.HOTP, code, step, v.Options.before(), v.Options.after())
+	if !ok {
+		return 0, failed(att, ErrInvalidCode)
+	}
+	if err := v.useStep(step + uint64(int64(off))); err != nil {
+		return 0, failed(att, err)
+	}
+	succeeded(att)
+	return off, nil
+}
+
+// VerifyHOTP checks code as an HOTP code subject to the look-ahead settings
+// of v.Options. On success it returns the matching counter value as for
+// [Config.VerifyHOTP], and records the counter in v.Store. If code does not
+// match, it reports ErrInvalidCode; if the counter matched was already used,
+// it reports ErrReplayed. If the subject is throttled, it reports a
+// *ThrottleError.
+//
+// The look-ahead window starts after the later of v.Config.Counter and the
+// last counter recorded in v.Store, so successive codes are accepted without
+// updating v.Config. If v.Store is nil, the caller must store the returned
+// counter as the new value of v.Config.Counter, or the window will not
+// advance and later codes will eventually be rejected.
+func (v Verifier) VerifyHOTP(code string) (uint64, error) {
+	att, err := v.begin()
+	if err != nil {
+		return 0, err
+	}
+	next, before := v.Config.Counter+1, 0
+	if v.Store != nil {
+		last, ok, err := v.Store.LastStep(v.Subject)
+		if err != nil {
+			return 0, failed(att, err)
+		} else if ok && last >= v.Config.Counter {
+			// Include the last counter used, so that resubmitting the most
+			// recent code is reported as a replay.
+			next, before = last+1, 1
+		}
+	}
+	off, ok := verifyWindow(v.Config.HOTP, code, next, before, v.Options.lookAhead())
+	if !ok {
+		return 0, failed(att, ErrInvalidCode)
+	}
+	ctr := next + uint64(int64(off))
+	if err := v.useStep(ctr); err != nil {
+		return 0, failed(att, err)
+	}
+	succeeded(att)
+	return ctr, nil
+}
+
+func (v Verifier) useStep(step uint64) error {
+	if v.Store == nil {
+		return nil
+	}
+	return v.Store.UseStep(v.Subject, step)
+}
+
+// begin reserves an attempt with v.Throttle. It returns nil without error if
+// v.Throttle is nil.
+func (v Verifier) begin() (*ThrottleAttempt, error) {
+	if v.Throttle == nil {
+		return nil, nil
+	}
+	return v.Throttle.Begin(v.Subject)
+}
+
+// failed resolves att according to err, and returns err. If err indicates
+// the code was rejected, the attempt is recorded as a failure, and if that
+// causes a lockout, the result wraps both err and the *ThrottleError. For any
+// other error, the attempt is cancelled.
+func failed(att *ThrottleAttempt, err error) error {
+	if att == nil {
+		return err
+	} else if !(errors.Is(err, ErrInvalidCode) || errors.Is(err, ErrReplayed)) {
+		att.Cancel()
+		return err
+	}
+	if terr := att.Failed(); terr != nil {
+		return errors.Join(err, terr)
+	}
+	return err
+}
+
+func succeeded(att *ThrottleAttempt) {
+	if att != nil {
+		att.Succeeded()
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp_test
+
+import (
+	"testing"
+
+	"github.com/creachadair/otp"
+)
+
+func TestConfig_VerifyTOTP(t *testing.T) {
+	const now = 1000
+	cfg := otp.Config{Key: "12345678901234567890", TimeStep: fixedTime(now)}
+
+	tests := []struct {
+		name   string
+		step   uint64
+		opts   *otp.VerifyOptions
+		offset int
+		ok     bool
+	}{
+		{"Exact", now, nil, 0, true},
+		{"ExactOnlyEarly", now - 1, nil, 0, false},
+		{"ExactOnlyLate", now + 1, nil, 0, false},
+		{"OneBefore", now - 1, &otp.VerifyOptions{Before: 1}, -1, true},
+		{"TwoBefore", now - 2, &otp.VerifyOptions{Before: 1}, 0, false},
+		{"OneAfter", now + 1, &otp.VerifyOptions{After: 1}, 1, true},
+		{"AfterNotBefore", now - 1, &otp.VerifyOptions{After: 3}, 0, false},
+		{"Wide", now + 4, &otp.VerifyOptions{Before: 5, After: 5}, 4, true},
+		{"NegativeIgnored", now - 1, &otp.VerifyOptions{Before: -3}, 0, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			code := cfg.HOTP(tc.step)
+			offset, ok := cfg.VerifyTOTP(code, tc.opts)
+			if ok != tc.ok || offset != tc.offset {
+				t.Errorf("VerifyTOTP(%q): got (%d, %v), want (%d, %v)", code, offset, ok, tc.offset, tc.ok)
+			}
+		})
+	}
+
+	t.Run("WrongCode", func(t *testing.T) {
+		if off, ok := cfg.VerifyTOTP("not-a-code", &otp.VerifyOptions{Before: 2, After: 2}); ok {
+			t.Errorf("VerifyTOTP: unexpectedly matched at offset %d", off)
+		}
+	})
+
+	t.Run("StartOfTime", func(t *testing.T) {
+		cfg := otp.Config{Key: "12345678901234567890", TimeStep: fixedTime(1)}
+		off, ok := cfg.VerifyTOTP(cfg.HOTP(0), &otp.VerifyOptions{Before: 5})
+		if !ok || off != -1 {
+			t.Errorf("VerifyTOTP: got (%d, %v), want (-1, true)", off, ok)
+		}
+	})
+}
+
+func TestConfig_VerifyHOTP(t *testing.T) {
+	cfg := otp.Config{Key: "12345678901234567890", Counter: 10}
+
+	tests := []struct {
+		name  string
+		ctr   uint64
+		opts  *otp.VerifyOptions
+		want  uint64
+		match bool
+	}{
+		{"Next", 11, nil, 11, true},
+		{"Current", 10, nil, 0, false},
+		{"Past", 5, &otp.VerifyOptions{LookAhead: 10}, 0, false},
+		{"NoLookAhead", 12, nil, 0, false},
+		{"LookAhead", 14, &otp.VerifyOptions{LookAhead: 3}, 14, true},
+		{"BeyondLookAhead", 15, &otp.VerifyOptions{LookAhead: 3}, 0, false},
+		{"IgnoresSkew", 9, &otp.VerifyOptions{Before: 5}, 0, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			code := cfg.HOTP(tc.ctr)
+			got, ok := cfg.VerifyHOTP(code, tc.opts)
+			if ok != tc.match || got != tc.want {
+				t.Errorf("VerifyHOTP(%q): got (%d, %v), want (%d, %v)", code, got, ok, tc.want, tc.match)
+			}
+		})
+	}
+}
+
+func TestConfig_ResyncHOTP(t *testing.T) {
+	cfg := otp.Config{Key: "12345678901234567890", Counter: 10}
+
+	tests := []struct {
+		name         string
+		code1, code2 string
+		opts         *otp.VerifyOptions
+		want         uint64
+		match        bool
+	}{
+		{"Default", cfg.HOTP(60), cfg.HOTP(61), nil, 61, true},
+		{"Adjacent", cfg.HOTP(11), cfg.HOTP(12), nil, 12, true},
+		{"NotConsecutive", cfg.HOTP(60), cfg.HOTP(62), nil, 0, false},
+		{"WrongOrder", cfg.HOTP(61), cfg.HOTP(60), nil, 0, false},
+		{"OutOfWindow", cfg.HOTP(200), cfg.HOTP(201), nil, 0, false},
+		{"WideWindow", cfg.HOTP(200), cfg.HOTP(201), &otp.VerifyOptions{ResyncWindow: 500}, 201, true},
+		{"Stale", cfg.HOTP(5), cfg.HOTP(6), nil, 0, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := cfg.ResyncHOTP(tc.code1, tc.code2, tc.opts)
+			if ok != tc.match || got != tc.want {
+				t.Errorf("ResyncHOTP(%q, %q): got (%d, %v), want (%d, %v)",
+					tc.code1, tc.code2, got, ok, tc.want, tc.match)
+			}
+		})
+	}
+}
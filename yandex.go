@@ -0,0 +1,59 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// YandexSecretLength is the length in bytes of a Yandex.Key secret. Secrets
+// distributed by Yandex may be longer, but only this prefix is used.
+const YandexSecretLength = 16
+
+// YandexDigits is the number of letters in a Yandex.Key code.
+const YandexDigits = 8
+
+// YandexConfig returns a Config that generates Yandex.Key codes for the given
+// secret and PIN. It reports an error if the secret is shorter than
+// YandexSecretLength.
+//
+// The HMAC key is the SHA-256 digest of the PIN followed by the secret, with
+// a leading zero byte removed. Codes are computed with HMAC-SHA256 over a
+// 30-second time step, and the truncated value is formatted as 8 lowercase
+// letters.
+func YandexConfig(secret []byte, pin string) (Config, error) {
+	if len(secret) < YandexSecretLength {
+		return Config{}, fmt.Errorf("secret is %d bytes, want at least %d", len(secret), YandexSecretLength)
+	}
+	h := sha256.New()
+	h.Write([]byte(pin))
+	h.Write(secret[:YandexSecretLength])
+	key := h.Sum(nil)
+	if key[0] == 0 {
+		key = key[1:]
+	}
+	return Config{
+		Key:       string(key),
+		Algorithm: SHA256,
+		Period:    30 * time.Second,
+		Digits:    YandexDigits,
+		Format:    formatYandex,
+	}, nil
+}
+
+// formatYandex truncates hmac as in RFC 4226, but taking 63 bits rather than
+// 31, and renders the result in base 26 using the letters a to z, most
+// significant first.
+func formatYandex(hmac []byte, width int) string {
+	offset := hmac[len(hmac)-1] & 0x0f
+	code := binary.BigEndian.Uint64(hmac[offset:]) & 0x7fffffffffffffff
+	out := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		out[i] = 'a' + byte(code%26)
+		code /= 26
+	}
+	return string(out)
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2026 Michael J. Fromberger. All Rights Reserved.
+
+package otp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/creachadair/otp"
+)
+
+func TestYandexConfig(t *testing.T) {
+	// Reference codes published with the Aegis authenticator.
+	tests := []struct {
+		pin, secret string
+		unix        int64
+		want        string
+	}{
+		{"5239", "6SB2IKNM6OBZPAVBVTOHDKS4FAAAAAAADFUTQMBTRY", 1641559648, "umozdicq"},
+		{"7586", "LA2V6KMCGYMWWVEW64RNP3JA3IAAAAAAHTSG4HRZPI", 1581064020, "oactmacq"},
+		{"7586", "LA2V6KMCGYMWWVEW64RNP3JA3IAAAAAAHTSG4HRZPI", 1581090810, "wemdwrix"},
+		{"5210481216086702", "JBGSAU4G7IEZG6OY4UAXX62JU4AAAAAAHTSG4HXU3M", 1581091469, "dfrpywob"},
+		{"5210481216086702", "JBGSAU4G7IEZG6OY4UAXX62JU4AAAAAAHTSG4HXU3M", 1581093059, "vunyprpd"},
+	}
+	for _, tc := range tests {
+		secret, err := otp.ParseKey(tc.secret)
+		if err != nil {
+			t.Fatalf("ParseKey(%q): unexpected error: %v", tc.secret, err)
+		}
+		cfg, err := otp.YandexConfig(secret, tc.pin)
+		if err != nil {
+			t.Fatalf("YandexConfig: unexpected error: %v", err)
+		}
+		now := time.Unix(tc.unix, 0)
+		if got := cfg.TOTPAt(now); got != tc.want {
+			t.Errorf("TOTPAt(%d) [pin %q]: got %q, want %q", tc.unix, tc.pin, got, tc.want)
+		}
+		cfg.TimeStep = func() uint64 { return cfg.StepAt(now) }
+		if _, ok := cfg.VerifyTOTP(tc.want, nil); !ok {
+			t.Errorf("VerifyTOTP(%q): got false, want true", tc.want)
+		}
+	}
+
+	if cfg, err := otp.YandexConfig([]byte("too short"), "1234"); err == nil {
+		t.Errorf("YandexConfig: got %+v, want error", cfg)
+	}
+}